@@ -0,0 +1,428 @@
+// Package buzhashtest provides a conformance suite for RollingHash
+// implementations.
+//
+// Implementations that hash the same bytes with the buzhash table, e.g. over
+// memory-mapped segments, can run TestRollingHash from their own tests to
+// check that they behave exactly like the reference Hasher. The subtests for
+// the optional MatchRoller, FuncRoller and Cloner methods are skipped for
+// implementations without them:
+//
+//	func TestConformance(t *testing.T) {
+//		buzhashtest.TestRollingHash(t, func(buf []byte, window uint32) (buzhash.RollingHash, error) {
+//			return NewMappedHasher(buf, window)
+//		})
+//	}
+package buzhashtest
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/satmihir/buzhash"
+)
+
+// Factory creates the RollingHash under test over buf with the window
+// starting at 0, like buzhash.New.
+type Factory func(buf []byte, windowSize uint32) (buzhash.RollingHash, error)
+
+// GoldenVector pins the output of BulkRoll for an input.
+type GoldenVector struct {
+	Input      []byte
+	WindowSize uint32
+	Stride     uint32
+	Hashes     []uint64
+}
+
+// Returns the bytes 0, 1, ..., n-1.
+func sequence(n int) []byte {
+	seq := make([]byte, n)
+	for i := range seq {
+		seq[i] = byte(i)
+	}
+	return seq
+}
+
+// GoldenVectors are reference outputs of buzhash.TableV1, the table behind
+// buzhash.New. A conforming implementation must reproduce them exactly.
+var GoldenVectors = []GoldenVector{
+	{
+		Input: []byte("hello world"), WindowSize: 3, Stride: 1,
+		Hashes: []uint64{0x5beda40fb9e42c75, 0x19c2a1fff052aeb8, 0x4a00f0c9b2731c04, 0xd54cb6eab3174223,
+			0x60de7ba62b16d30f, 0x42fcee2ae1204c5d, 0xc9d93604b85e7f0e, 0xa6e0d845e92775c7, 0x345970173f3754af},
+	},
+	{
+		Input: []byte("abcdefghijk"), WindowSize: 4, Stride: 2,
+		Hashes: []uint64{0xb152348aeaa87bab, 0xe42e9fd19fccadd3, 0x735e0f33040ac8d6, 0x53f9e9a301c202ab},
+	},
+	{
+		Input: []byte("The quick brown fox jumps over the lazy dog"), WindowSize: 6, Stride: 5,
+		Hashes: []uint64{0x89abd3d28e60aca1, 0xdbb04bf05db71640, 0x3aa2b34d96756fea, 0x52ac705e4344fa0,
+			0xfb1a0410aebcdec0, 0xd5752d193df76fbc, 0xca2a31bcefa28971, 0xce0eaefc8659d00e},
+	},
+	{
+		Input: sequence(256), WindowSize: 8, Stride: 16,
+		Hashes: []uint64{0xef3a627dcd61a62b, 0xc7be1bca749e5b02, 0xaffc626910447df, 0xebab4af0f85e0375,
+			0x93a127087af69f36, 0xf2a5c05b535f37d0, 0x1d45fcb8f78c8f6f, 0x6bde3f5178f3991a,
+			0xbc82525d04560d88, 0xe280f3631c0b1778, 0xf99de9f62771b316, 0x7ad75cb85edfad48,
+			0xb192f4dba6ce01f, 0x547aa731d14025ff, 0x266c27255809155a, 0x784e764ec6ff8e5f},
+	},
+	{
+		// Windows longer than 64 bytes wrap the rotation around.
+		Input: sequence(100), WindowSize: 70, Stride: 7,
+		Hashes: []uint64{0x81c381911e3b2243, 0x4b34124b66f2a812, 0x3934fa38a22e2f6b, 0xa6b55214b313f563, 0x5592c72b6518db05},
+	},
+	{
+		Input: []byte("a"), WindowSize: 1, Stride: 1,
+		Hashes: []uint64{0xb054905d9a5189a9},
+	},
+}
+
+// TestRollingHash runs the conformance suite against the implementation
+// created by factory, one subtest per property.
+func TestRollingHash(t *testing.T, factory Factory) {
+	t.Helper()
+
+	t.Run("GoldenVectors", func(t *testing.T) { testGoldenVectors(t, factory) })
+	t.Run("RollMatchesRecompute", func(t *testing.T) { testRollMatchesRecompute(t, factory) })
+	t.Run("RollMultiStep", func(t *testing.T) { testRollMultiStep(t, factory) })
+	t.Run("BulkRollStride", func(t *testing.T) { testBulkRollStride(t, factory) })
+	t.Run("BulkRollMatch", func(t *testing.T) { testBulkRollMatch(t, factory) })
+	t.Run("BulkRollFunc", func(t *testing.T) { testBulkRollFunc(t, factory) })
+	t.Run("Reset", func(t *testing.T) { testReset(t, factory) })
+	t.Run("Clone", func(t *testing.T) { testClone(t, factory) })
+	t.Run("Errors", func(t *testing.T) { testErrors(t, factory) })
+	t.Run("Hash64", func(t *testing.T) { testHash64(t, factory) })
+}
+
+func mustNew(t *testing.T, factory Factory, buf []byte, windowSize uint32) buzhash.RollingHash {
+	t.Helper()
+
+	h, err := factory(buf, windowSize)
+	if err != nil {
+		t.Fatalf("factory(len %d, window %d) failed: %v", len(buf), windowSize, err)
+	}
+	return h
+}
+
+// The reference window hashes at the given stride from position start.
+func expectedWindows(buf []byte, windowSize, start, stride uint32) []uint64 {
+	var hashes []uint64
+	for i := start; i+windowSize <= uint32(len(buf)); i += stride {
+		hashes = append(hashes, buzhash.Hash(buf[i:i+windowSize]))
+	}
+	return hashes
+}
+
+func randomBuffer(rng *rand.Rand, n int) []byte {
+	buf := make([]byte, n)
+	rng.Read(buf)
+	return buf
+}
+
+func testGoldenVectors(t *testing.T, factory Factory) {
+	for i, v := range GoldenVectors {
+		h := mustNew(t, factory, v.Input, v.WindowSize)
+		if got := h.Sum64(); got != v.Hashes[0] {
+			t.Errorf("vector %d: Sum64() = %#x, want %#x", i, got, v.Hashes[0])
+		}
+
+		got, err := h.BulkRoll(v.Stride)
+		if err != nil {
+			t.Fatalf("vector %d: BulkRoll(%d) failed: %v", i, v.Stride, err)
+		}
+		if !slices.Equal(got, v.Hashes) {
+			t.Errorf("vector %d: BulkRoll(%d) = %#v, want %#v", i, v.Stride, got, v.Hashes)
+		}
+	}
+}
+
+func testRollMatchesRecompute(t *testing.T, factory Factory) {
+	rng := rand.New(rand.NewSource(1))
+
+	for trial := 0; trial < 50; trial++ {
+		buf := randomBuffer(rng, 1+rng.Intn(200))
+		windowSize := uint32(1 + rng.Intn(len(buf)))
+		h := mustNew(t, factory, buf, windowSize)
+
+		if got, want := h.Sum64(), buzhash.Hash(buf[:windowSize]); got != want {
+			t.Fatalf("initial hash = %#x, want %#x", got, want)
+		}
+
+		for i := uint32(1); i+windowSize <= uint32(len(buf)); i++ {
+			got, err := h.Roll(1)
+			if err != nil {
+				t.Fatalf("Roll(1) to offset %d failed: %v", i, err)
+			}
+			if want := buzhash.Hash(buf[i : i+windowSize]); got != want {
+				t.Fatalf("Roll(1) at offset %d = %#x, want %#x", i, got, want)
+			}
+			if got != h.Sum64() {
+				t.Fatalf("Sum64() at offset %d = %#x, want %#x", i, h.Sum64(), got)
+			}
+			if h.Position() != i {
+				t.Fatalf("Position() = %d, want %d", h.Position(), i)
+			}
+		}
+
+		if _, err := h.Roll(1); !errors.Is(err, buzhash.ErrIllegalRoll) {
+			t.Fatalf("Roll(1) past the end returned %v, want ErrIllegalRoll", err)
+		}
+	}
+}
+
+func testRollMultiStep(t *testing.T, factory Factory) {
+	buf := []byte("abcdefghijklmnopqrstuvwxyz")
+	h := mustNew(t, factory, buf, 5)
+
+	pos := uint32(0)
+	for _, step := range []uint32{0, 3, 1, 7, 10} {
+		got, err := h.Roll(step)
+		if err != nil {
+			t.Fatalf("Roll(%d) from %d failed: %v", step, pos, err)
+		}
+		pos += step
+		if want := buzhash.Hash(buf[pos : pos+5]); got != want {
+			t.Fatalf("Roll(%d) to %d = %#x, want %#x", step, pos, got, want)
+		}
+	}
+
+	// A failed roll leaves the state untouched.
+	before := h.Sum64()
+	if _, err := h.Roll(uint32(len(buf))); !errors.Is(err, buzhash.ErrIllegalRoll) {
+		t.Fatalf("Roll past the end returned %v, want ErrIllegalRoll", err)
+	}
+	if h.Position() != pos || h.Sum64() != before {
+		t.Fatalf("failed Roll changed the state to position %d hash %#x", h.Position(), h.Sum64())
+	}
+}
+
+func testBulkRollStride(t *testing.T, factory Factory) {
+	rng := rand.New(rand.NewSource(2))
+	buf := randomBuffer(rng, 150)
+	h := mustNew(t, factory, buf, 7)
+
+	if _, err := h.Roll(4); err != nil {
+		t.Fatalf("Roll(4) failed: %v", err)
+	}
+	before := h.Sum64()
+
+	for stride := uint32(1); stride <= 9; stride++ {
+		got, err := h.BulkRoll(stride)
+		if err != nil {
+			t.Fatalf("BulkRoll(%d) failed: %v", stride, err)
+		}
+		if want := expectedWindows(buf, 7, 4, stride); !slices.Equal(got, want) {
+			t.Fatalf("BulkRoll(%d) = %#v, want %#v", stride, got, want)
+		}
+	}
+
+	if h.Position() != 4 || h.Sum64() != before {
+		t.Fatalf("BulkRoll changed the state to position %d hash %#x", h.Position(), h.Sum64())
+	}
+
+	if _, err := h.BulkRoll(0); !errors.Is(err, buzhash.ErrIllegalStride) {
+		t.Fatalf("BulkRoll(0) returned %v, want ErrIllegalStride", err)
+	}
+}
+
+func testBulkRollMatch(t *testing.T, factory Factory) {
+	rng := rand.New(rand.NewSource(3))
+	buf := randomBuffer(rng, 2000)
+	h, ok := mustNew(t, factory, buf, 6).(buzhash.MatchRoller)
+	if !ok {
+		t.Skip("the implementation is not a MatchRoller")
+	}
+
+	if _, err := h.Roll(3); err != nil {
+		t.Fatalf("Roll(3) failed: %v", err)
+	}
+
+	for _, stride := range []uint32{1, 3} {
+		var wantPos []uint32
+		var wantHashes []uint64
+		for i := uint32(3); i+6 <= uint32(len(buf)); i += stride {
+			if hash := buzhash.Hash(buf[i : i+6]); hash&0x1f == 0x3 {
+				wantPos = append(wantPos, i)
+				wantHashes = append(wantHashes, hash)
+			}
+		}
+
+		positions, hashes, err := h.BulkRollMatch(0x1f, 0x3, stride)
+		if err != nil {
+			t.Fatalf("BulkRollMatch(stride %d) failed: %v", stride, err)
+		}
+		if !slices.Equal(positions, wantPos) || !slices.Equal(hashes, wantHashes) {
+			t.Fatalf("BulkRollMatch(stride %d) = %v %#v, want %v %#v", stride, positions, hashes, wantPos, wantHashes)
+		}
+	}
+
+	if h.Position() != 3 {
+		t.Fatalf("BulkRollMatch changed the position to %d", h.Position())
+	}
+
+	if _, _, err := h.BulkRollMatch(0, 0, 0); !errors.Is(err, buzhash.ErrIllegalStride) {
+		t.Fatalf("BulkRollMatch with stride 0 returned %v, want ErrIllegalStride", err)
+	}
+}
+
+func testBulkRollFunc(t *testing.T, factory Factory) {
+	buf := []byte("the quick brown fox jumps over the lazy dog")
+	h, ok := mustNew(t, factory, buf, 4).(buzhash.FuncRoller)
+	if !ok {
+		t.Skip("the implementation is not a FuncRoller")
+	}
+
+	if _, err := h.Roll(2); err != nil {
+		t.Fatalf("Roll(2) failed: %v", err)
+	}
+	before := h.Sum64()
+
+	var got []uint64
+	err := h.BulkRollFunc(3, func(pos uint32, hash uint64) bool {
+		if want := uint32(2 + 3*len(got)); pos != want {
+			t.Fatalf("BulkRollFunc visited position %d, want %d", pos, want)
+		}
+		got = append(got, hash)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("BulkRollFunc failed: %v", err)
+	}
+	if want := expectedWindows(buf, 4, 2, 3); !slices.Equal(got, want) {
+		t.Fatalf("BulkRollFunc = %#v, want %#v", got, want)
+	}
+
+	calls := 0
+	if err := h.BulkRollFunc(1, func(uint32, uint64) bool { calls++; return calls < 5 }); err != nil {
+		t.Fatalf("BulkRollFunc with early stop failed: %v", err)
+	}
+	if calls != 5 {
+		t.Fatalf("BulkRollFunc called fn %d times after it returned false, want 5", calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = h.BulkRollContext(ctx, 1, func(uint32, uint64) bool { return true })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("BulkRollContext with a cancelled context returned %v", err)
+	}
+
+	if h.Position() != 2 || h.Sum64() != before {
+		t.Fatalf("BulkRollFunc changed the state to position %d hash %#x", h.Position(), h.Sum64())
+	}
+
+	if err := h.BulkRollFunc(0, func(uint32, uint64) bool { return true }); !errors.Is(err, buzhash.ErrIllegalStride) {
+		t.Fatalf("BulkRollFunc(0) returned %v, want ErrIllegalStride", err)
+	}
+}
+
+func testReset(t *testing.T, factory Factory) {
+	buf := []byte("abcdefghijk")
+	h := mustNew(t, factory, buf, 4)
+	initial := h.Sum64()
+
+	if _, err := h.Roll(3); err != nil {
+		t.Fatalf("Roll(3) failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		h.Reset()
+		if h.Position() != 0 || h.Sum64() != initial {
+			t.Fatalf("Reset left position %d hash %#x, want 0 %#x", h.Position(), h.Sum64(), initial)
+		}
+	}
+
+	got, err := h.Roll(1)
+	if err != nil {
+		t.Fatalf("Roll(1) after Reset failed: %v", err)
+	}
+	if want := buzhash.Hash(buf[1:5]); got != want {
+		t.Fatalf("Roll(1) after Reset = %#x, want %#x", got, want)
+	}
+}
+
+func testClone(t *testing.T, factory Factory) {
+	buf := randomBuffer(rand.New(rand.NewSource(5)), 200)
+	h, ok := mustNew(t, factory, buf, 16).(buzhash.Cloner)
+	if !ok {
+		t.Skip("the implementation is not a Cloner")
+	}
+
+	if _, err := h.Roll(50); err != nil {
+		t.Fatalf("Roll(50) failed: %v", err)
+	}
+
+	c := h.Clone()
+	if c.Position() != h.Position() || c.Sum64() != h.Sum64() {
+		t.Fatalf("Clone at position %d hash %#x, want %d %#x", c.Position(), c.Sum64(), h.Position(), h.Sum64())
+	}
+
+	// Both continue independently from the same point.
+	if _, err := c.Roll(30); err != nil {
+		t.Fatalf("Roll(30) on the clone failed: %v", err)
+	}
+	if _, err := h.Roll(7); err != nil {
+		t.Fatalf("Roll(7) failed: %v", err)
+	}
+	if want := buzhash.Hash(buf[80:96]); c.Position() != 80 || c.Sum64() != want {
+		t.Fatalf("clone at position %d hash %#x, want 80 %#x", c.Position(), c.Sum64(), want)
+	}
+	if want := buzhash.Hash(buf[57:73]); h.Position() != 57 || h.Sum64() != want {
+		t.Fatalf("original at position %d hash %#x, want 57 %#x", h.Position(), h.Sum64(), want)
+	}
+
+	hashes, err := c.BulkRoll(1)
+	if err != nil {
+		t.Fatalf("BulkRoll(1) on the clone failed: %v", err)
+	}
+	if want := expectedWindows(buf, 16, 80, 1); !slices.Equal(hashes, want) {
+		t.Fatalf("BulkRoll(1) on the clone = %v, want %v", hashes, want)
+	}
+
+	c.Reset()
+	if c.Position() != 0 || h.Position() != 57 {
+		t.Fatalf("Reset of the clone moved the original to %d", h.Position())
+	}
+}
+
+func testErrors(t *testing.T, factory Factory) {
+	if _, err := factory([]byte("abc"), 4); !errors.Is(err, buzhash.ErrWindowTooLong) {
+		t.Fatalf("window longer than the buffer returned %v, want ErrWindowTooLong", err)
+	}
+
+	h := mustNew(t, factory, []byte("abc"), 3)
+	if _, err := h.Roll(1); !errors.Is(err, buzhash.ErrIllegalRoll) {
+		t.Fatalf("Roll with a full-buffer window returned %v, want ErrIllegalRoll", err)
+	}
+}
+
+func testHash64(t *testing.T, factory Factory) {
+	h := mustNew(t, factory, []byte("hello world"), 5)
+
+	var _ hash.Hash64 = h
+
+	if h.Size() != 8 {
+		t.Errorf("Size() = %d, want 8", h.Size())
+	}
+	if h.BlockSize() != 1 {
+		t.Errorf("BlockSize() = %d, want 1", h.BlockSize())
+	}
+
+	out := h.Sum([]byte{1, 2, 3})
+	if len(out) != 11 || !slices.Equal(out[:3], []byte{1, 2, 3}) {
+		t.Fatalf("Sum did not append 8 bytes: %v", out)
+	}
+	if got := binary.BigEndian.Uint64(out[3:]); got != h.Sum64() {
+		t.Errorf("Sum = %#x, want big-endian Sum64 %#x", got, h.Sum64())
+	}
+
+	n, err := h.Write([]byte("abc"))
+	if n != 0 || !errors.Is(err, buzhash.ErrNotWritable) {
+		t.Errorf("Write = %d, %v, want 0, ErrNotWritable", n, err)
+	}
+}
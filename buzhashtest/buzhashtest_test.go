@@ -0,0 +1,73 @@
+package buzhashtest
+
+import (
+	"testing"
+
+	"github.com/satmihir/buzhash"
+)
+
+func TestHasher(t *testing.T) {
+	TestRollingHash(t, buzhash.New)
+}
+
+func TestHasherWithOptions(t *testing.T) {
+	table, err := buzhash.LookupTable(buzhash.TableV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goBackend, err := buzhash.LookupBackend(buzhash.BackendGo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	TestRollingHash(t, func(buf []byte, windowSize uint32) (buzhash.RollingHash, error) {
+		return buzhash.NewWithOptions(buf, windowSize, buzhash.WithTable(table), buzhash.WithBackend(goBackend))
+	})
+}
+
+func TestAppendableHasher(t *testing.T) {
+	TestRollingHash(t, func(buf []byte, windowSize uint32) (buzhash.RollingHash, error) {
+		if windowSize > uint32(len(buf)) {
+			return nil, buzhash.ErrWindowTooLong
+		}
+
+		// Start short of a window and append the rest in pieces.
+		h := buzhash.NewAppendable(nil, windowSize)
+		for i, size := 0, 1; i < len(buf); i, size = i+size, size*2 {
+			h.Append(buf[i:min(i+size, len(buf))])
+		}
+		return h, nil
+	})
+}
+
+func TestSegmentedHasher(t *testing.T) {
+	TestRollingHash(t, func(buf []byte, windowSize uint32) (buzhash.RollingHash, error) {
+		// Split into uneven segments including an empty one.
+		var segs [][]byte
+		for i, size := 0, 1; i < len(buf); i, size = i+size, size%5+1 {
+			segs = append(segs, buf[i:min(i+size, len(buf))], nil)
+		}
+		return buzhash.NewSegmented(segs, windowSize)
+	})
+}
+
+func TestContiguousSpacedSeed(t *testing.T) {
+	TestRollingHash(t, func(buf []byte, windowSize uint32) (buzhash.RollingHash, error) {
+		pattern := make([]byte, windowSize)
+		for i := range pattern {
+			pattern[i] = '1'
+		}
+
+		seed, err := buzhash.ParseSpacedSeed(string(pattern))
+		if err != nil {
+			return nil, err
+		}
+		return buzhash.NewSpaced(buf, seed)
+	})
+}
+
+func TestMutableHasher(t *testing.T) {
+	TestRollingHash(t, func(buf []byte, windowSize uint32) (buzhash.RollingHash, error) {
+		return buzhash.NewMutable(buf, windowSize)
+	})
+}
@@ -0,0 +1,59 @@
+// Command buzquality reports on the statistical quality of buzhash over the
+// sliding windows of a file.
+//
+//	buzquality -window 6 -format json testdata/book.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/satmihir/buzhash"
+	"github.com/satmihir/buzhash/quality"
+)
+
+func main() {
+	window := flag.Int("window", 6, "the number of bytes per window")
+	bins := flag.Int("bins", 65536, "the number of bins for the uniformity test")
+	samples := flag.Int("samples", 256, "the number of windows sampled for the avalanche test")
+	collisionBits := flag.Int("collision-bits", 32, "the number of low hash bits compared for collisions")
+	format := flag.String("format", "text", "the output format, text or json")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] file\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *format, quality.Config{
+		WindowSize:       *window,
+		Bins:             *bins,
+		AvalancheSamples: *samples,
+		CollisionBits:    *collisionBits,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "buzquality: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(path, format string, cfg quality.Config) error {
+	corpus, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	report, err := quality.Analyze(buzhash.Hash, corpus, cfg)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		return report.WriteJSON(os.Stdout)
+	}
+	return report.WriteText(os.Stdout)
+}
@@ -0,0 +1,195 @@
+// Package dna hashes nucleotide k-mers with buzhash, in the spirit of ntHash.
+//
+// Every base is mapped to the buzhash table entry of its upper-case letter,
+// so the forward hash of a k-mer equals buzhash.Hash over that k-mer. The
+// hash of the reverse complement is rolled alongside, and the canonical hash
+// (the smaller of the two) is the same for a k-mer and its reverse
+// complement, independently of the strand it was read from.
+package dna
+
+import (
+	"errors"
+	"math/bits"
+
+	"github.com/satmihir/buzhash"
+)
+
+var ErrIllegalK = errors.New("the k-mer size must be at least 1")
+
+// Maps a base to its index in seeds or -1 when the byte is not a base.
+var codes [256]int8
+
+// The seed of every base indexed by its code, A, C, G, T.
+var seeds [4]uint64
+
+func init() {
+	for i := range codes {
+		codes[i] = -1
+	}
+
+	for i, b := range []byte("ACGT") {
+		codes[b] = int8(i)
+		codes[b+'a'-'A'] = int8(i)
+		seeds[i] = buzhash.Hash([]byte{b})
+	}
+}
+
+// The complement of the code c is 3-c since the bases are ordered A, C, G, T.
+func complement(c int8) int8 {
+	return 3 - c
+}
+
+// Kmer holds the hashes of the k-mer starting at Position.
+type Kmer struct {
+	Position  uint32
+	Forward   uint64
+	Reverse   uint64
+	Canonical uint64
+}
+
+// Hasher iterates over the valid k-mers of a sequence, rolling the forward
+// and reverse complement hashes together. Windows containing N or any other
+// byte that is not A, C, G or T (in either case) are skipped.
+type Hasher struct {
+	// The immutable sequence to hash over
+	seq []byte
+	// The k-mer size
+	k uint32
+	// The start of the current k-mer
+	position uint32
+	// The forward hash of the current k-mer
+	fwd uint64
+	// The reverse complement hash of the current k-mer
+	rev uint64
+	// Whether the hasher points at a valid k-mer
+	started bool
+	// Set once the sequence is exhausted
+	done bool
+}
+
+// Creates a new k-mer hasher over the sequence. Call Next to move to the
+// first valid k-mer.
+func New(seq []byte, k uint32) (*Hasher, error) {
+	if k == 0 {
+		return nil, ErrIllegalK
+	}
+
+	return &Hasher{seq: seq, k: k}, nil
+}
+
+// Next advances to the next valid k-mer and reports whether there is one.
+// Rolling costs O(1) per base; after an invalid base the next k-mer is
+// hashed from scratch.
+func (h *Hasher) Next() bool {
+	if h.done {
+		return false
+	}
+
+	from := uint32(0)
+	if h.started {
+		in := uint64(h.position) + uint64(h.k)
+		if in >= uint64(len(h.seq)) {
+			h.done = true
+			return false
+		}
+
+		if c := codes[h.seq[in]]; c >= 0 {
+			out := codes[h.seq[h.position]]
+			h.fwd = bits.RotateLeft64(h.fwd, 1) ^
+				bits.RotateLeft64(seeds[out], int(h.k)) ^
+				seeds[c]
+			h.rev = bits.RotateLeft64(h.rev^seeds[complement(out)], -1) ^
+				bits.RotateLeft64(seeds[complement(c)], int(h.k)-1)
+			h.position++
+			return true
+		}
+
+		from = uint32(in) + 1
+	}
+
+	// Find the next run of k valid bases.
+	start := from
+	for i := from; uint64(i) < uint64(len(h.seq)); i++ {
+		if codes[h.seq[i]] < 0 {
+			start = i + 1
+			continue
+		}
+
+		if i-start+1 == h.k {
+			h.position = start
+			h.fwd, h.rev = hashKmer(h.seq[start : start+h.k])
+			h.started = true
+			return true
+		}
+	}
+
+	h.done = true
+	return false
+}
+
+// Position returns the start of the current k-mer.
+func (h *Hasher) Position() uint32 {
+	return h.position
+}
+
+// Forward returns the hash of the current k-mer as read.
+func (h *Hasher) Forward() uint64 {
+	return h.fwd
+}
+
+// Reverse returns the hash of the reverse complement of the current k-mer.
+func (h *Hasher) Reverse() uint64 {
+	return h.rev
+}
+
+// Canonical returns the smaller of the forward and reverse complement hashes.
+func (h *Hasher) Canonical() uint64 {
+	return min(h.fwd, h.rev)
+}
+
+// Kmer returns all hashes of the current k-mer.
+func (h *Hasher) Kmer() Kmer {
+	return Kmer{
+		Position:  h.position,
+		Forward:   h.fwd,
+		Reverse:   h.rev,
+		Canonical: h.Canonical(),
+	}
+}
+
+// Hashes returns every valid k-mer of the sequence along with the positions
+// of the invalid bases that caused windows to be skipped.
+func Hashes(seq []byte, k uint32) ([]Kmer, []uint32, error) {
+	h, err := New(seq, k)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var kmers []Kmer
+	for h.Next() {
+		kmers = append(kmers, h.Kmer())
+	}
+
+	var invalid []uint32
+	for i, b := range seq {
+		if codes[b] < 0 {
+			invalid = append(invalid, uint32(i))
+		}
+	}
+
+	return kmers, invalid, nil
+}
+
+// Hashes a k-mer of valid bases in one shot without rolling.
+func hashKmer(kmer []byte) (uint64, uint64) {
+	var fwd, rev uint64
+	n := len(kmer)
+
+	for i, b := range kmer {
+		c := codes[b]
+		fwd ^= bits.RotateLeft64(seeds[c], n-1-i)
+		rev ^= bits.RotateLeft64(seeds[complement(c)], i)
+	}
+
+	return fwd, rev
+}
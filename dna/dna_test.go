@@ -0,0 +1,118 @@
+package dna
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/satmihir/buzhash"
+	"github.com/stretchr/testify/assert"
+)
+
+func reverseComplement(seq []byte) []byte {
+	pairs := map[byte]byte{'A': 'T', 'C': 'G', 'G': 'C', 'T': 'A'}
+	out := make([]byte, len(seq))
+	for i, b := range bytes.ToUpper(seq) {
+		out[len(seq)-1-i] = pairs[b]
+	}
+	return out
+}
+
+func TestForwardMatchesBuzhash(t *testing.T) {
+	seq := []byte("ACGTTGCAacgtAGGCT")
+	h, err := New(seq, 5)
+	assert.NoError(t, err)
+
+	count := 0
+	for h.Next() {
+		kmer := bytes.ToUpper(seq[h.Position() : h.Position()+5])
+		assert.Equal(t, buzhash.Hash(kmer), h.Forward())
+		assert.Equal(t, buzhash.Hash(reverseComplement(kmer)), h.Reverse())
+		count++
+	}
+	assert.Equal(t, len(seq)-5+1, count)
+	assert.False(t, h.Next())
+}
+
+func TestCanonicalIsStrandIndependent(t *testing.T) {
+	seq := []byte("GATTACAGATTACACCGTAGGCTTAA")
+	k := uint32(7)
+
+	fwd, _, err := Hashes(seq, k)
+	assert.NoError(t, err)
+	rc, _, err := Hashes(reverseComplement(seq), k)
+	assert.NoError(t, err)
+	assert.Equal(t, len(fwd), len(rc))
+
+	for i := range fwd {
+		mirror := rc[len(rc)-1-i]
+		assert.Equal(t, fwd[i].Canonical, mirror.Canonical)
+		assert.Equal(t, fwd[i].Forward, mirror.Reverse)
+		assert.Equal(t, fwd[i].Canonical, min(fwd[i].Forward, fwd[i].Reverse))
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	upper, _, _ := Hashes([]byte("ACGTACGTTT"), 4)
+	lower, _, _ := Hashes([]byte("acgtACGTtt"), 4)
+	assert.Equal(t, upper, lower)
+}
+
+func TestSkipsInvalidBases(t *testing.T) {
+	seq := []byte("ACGTNACGTACxGT")
+	kmers, invalid, err := Hashes(seq, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{4, 11}, invalid)
+
+	var positions []uint32
+	for _, km := range kmers {
+		positions = append(positions, km.Position)
+		assert.Equal(t, buzhash.Hash(seq[km.Position:km.Position+3]), km.Forward)
+	}
+	assert.Equal(t, []uint32{0, 1, 5, 6, 7, 8}, positions)
+
+	kmers, _, _ = Hashes([]byte("NNNN"), 2)
+	assert.Empty(t, kmers)
+
+	kmers, _, _ = Hashes([]byte("AC"), 3)
+	assert.Empty(t, kmers)
+
+	_, err = New(seq, 0)
+	assert.ErrorIs(t, err, ErrIllegalK)
+}
+
+func FuzzRollingCorrectness(f *testing.F) {
+	f.Add([]byte("ACGTACGTNNACGT"), uint32(3))
+	f.Add([]byte("gattaca"), uint32(7))
+	f.Add([]byte("ACGT"), uint32(1))
+
+	f.Fuzz(func(t *testing.T, seq []byte, k uint32) {
+		if k == 0 || k > 64 {
+			return
+		}
+
+		h, err := New(seq, k)
+		assert.NoError(t, err)
+
+		var positions []uint32
+		for h.Next() {
+			pos := h.Position()
+			fwd, rev := hashKmer(seq[pos : pos+k])
+			assert.Equal(t, fwd, h.Forward(), "forward mismatch at offset %d", pos)
+			assert.Equal(t, rev, h.Reverse(), "reverse mismatch at offset %d", pos)
+			positions = append(positions, pos)
+		}
+
+		// Ground truth: every window made only of bases is visited.
+		var want []uint32
+		for i := 0; i+int(k) <= len(seq); i++ {
+			valid := true
+			for _, b := range seq[i : i+int(k)] {
+				valid = valid && codes[b] >= 0
+			}
+			if valid {
+				want = append(want, uint32(i))
+			}
+		}
+		assert.Equal(t, want, positions)
+	})
+}
@@ -0,0 +1,114 @@
+package fuzzy
+
+// Compare scores the similarity of two digests from 0 (unrelated) to 100
+// (identical). Digests whose block sizes are neither equal nor a factor of two
+// apart cannot be compared and score 0.
+func Compare(a, b string) (int, error) {
+	da, err := parse(a)
+	if err != nil {
+		return 0, err
+	}
+	db, err := parse(b)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case da.blockSize == db.blockSize:
+		if da.sig1 == db.sig1 && da.sig2 == db.sig2 {
+			return 100, nil
+		}
+		return max(
+			scoreStrings(da.sig1, db.sig1, da.blockSize),
+			scoreStrings(da.sig2, db.sig2, da.blockSize*2),
+		), nil
+	case da.blockSize == db.blockSize*2:
+		return scoreStrings(da.sig1, db.sig2, da.blockSize), nil
+	case db.blockSize == da.blockSize*2:
+		return scoreStrings(da.sig2, db.sig1, db.blockSize), nil
+	default:
+		return 0, nil
+	}
+}
+
+// Scores two signatures computed at the same block size.
+func scoreStrings(s1, s2 string, blockSize uint64) int {
+	if !hasCommonSubstring(s1, s2) {
+		return 0
+	}
+
+	// Scale the distance to the signature length and then to a percentage.
+	score := uint64(editDistance(s1, s2))
+	score = score * SignatureLength / uint64(len(s1)+len(s2))
+	score = 100 * score / SignatureLength
+	if score >= 100 {
+		return 0
+	}
+	score = 100 - score
+
+	// Small block sizes can't produce enough pieces to justify a high
+	// score, so cap it by the number of bytes the signatures stand for.
+	if blockSize < (99+RollingWindow)/RollingWindow*MinBlockSize {
+		limit := blockSize / MinBlockSize * uint64(min(len(s1), len(s2)))
+		score = min(score, limit)
+	}
+
+	return int(score)
+}
+
+// Reports whether the signatures share a run of at least RollingWindow
+// characters, below which matches are considered coincidental.
+func hasCommonSubstring(s1, s2 string) bool {
+	if len(s1) < RollingWindow || len(s2) < RollingWindow {
+		return false
+	}
+
+	seen := make(map[string]struct{}, len(s1))
+	for i := 0; i+RollingWindow <= len(s1); i++ {
+		seen[s1[i:i+RollingWindow]] = struct{}{}
+	}
+	for i := 0; i+RollingWindow <= len(s2); i++ {
+		if _, ok := seen[s2[i:i+RollingWindow]]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Weighted Levenshtein distance where a substitution costs as much as a
+// removal plus an insertion.
+func editDistance(s1, s2 string) int {
+	prev := make([]int, len(s2)+1)
+	cur := make([]int, len(s2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s1); i++ {
+		cur[0] = i
+		for j := 1; j <= len(s2); j++ {
+			replace := prev[j-1]
+			if s1[i-1] != s2[j-1] {
+				replace += 2
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, replace)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(s2)]
+}
+
+// Collapses runs of more than three identical characters, which carry little
+// information and would otherwise inflate scores.
+func eliminateSequences(s string) string {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if i >= 3 && s[i] == s[i-1] && s[i] == s[i-2] && s[i] == s[i-3] {
+			continue
+		}
+		out = append(out, s[i])
+	}
+	return string(out)
+}
@@ -0,0 +1,147 @@
+// Package fuzzy implements context-triggered piecewise hashing (CTPH) in the
+// style of ssdeep.
+//
+// The input is cut into pieces wherever a small buzhash window over the last
+// few bytes hits a trigger value that depends on the block size, and every
+// piece contributes one base64 character to the signature. Local edits only
+// change the characters of the pieces they touch, so similar inputs produce
+// similar signatures. Digests use the familiar `blocksize:sig1:sig2` layout
+// and Compare scores them with ssdeep's edit-distance based algorithm.
+//
+// The piece boundaries come from buzhash rather than ssdeep's own rolling
+// hash, so digests are only comparable with other digests produced by this
+// package, not with the ssdeep tool.
+package fuzzy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/satmihir/buzhash"
+)
+
+const (
+	// The size of the rolling window that triggers piece boundaries
+	RollingWindow = 7
+	// The smallest block size a digest can have
+	MinBlockSize = 3
+	// The maximum length of the first signature
+	SignatureLength = 64
+
+	hashPrime = 0x01000193
+	hashInit  = 0x28021967
+	b64       = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+)
+
+var ErrInvalidDigest = errors.New("invalid fuzzy digest")
+
+// Digest returns the fuzzy hash of buf as `blocksize:sig1:sig2`.
+func Digest(buf []byte) string {
+	blockSize := uint64(MinBlockSize)
+	for blockSize*SignatureLength < uint64(len(buf)) {
+		blockSize *= 2
+	}
+
+	for {
+		sig1, sig2 := signatures(buf, blockSize)
+		// Too few pieces carry little information, retry with smaller blocks.
+		if blockSize > MinBlockSize && len(sig1) < SignatureLength/2 {
+			blockSize /= 2
+			continue
+		}
+
+		return fmt.Sprintf("%d:%s:%s", blockSize, sig1, sig2)
+	}
+}
+
+// Computes the signatures for blockSize and twice blockSize in one pass.
+func signatures(buf []byte, blockSize uint64) (string, string) {
+	var sig1 [SignatureLength]byte
+	var sig2 [SignatureLength / 2]byte
+	j, k := 0, 0
+	h1, h2 := uint32(hashInit), uint32(hashInit)
+	// Whether bytes were hashed since the last boundary
+	pending1, pending2 := false, false
+
+	// The first window hash is available after RollingWindow bytes.
+	var roller buzhash.RollingHash
+	if len(buf) >= RollingWindow {
+		roller, _ = buzhash.New(buf, RollingWindow)
+	}
+
+	for i, c := range buf {
+		h1 = (h1 * hashPrime) ^ uint32(c)
+		h2 = (h2 * hashPrime) ^ uint32(c)
+		pending1, pending2 = true, true
+
+		if i+1 < RollingWindow {
+			continue
+		}
+
+		window := roller.Sum64()
+		if i+1 < len(buf) {
+			_, _ = roller.Roll(1)
+		}
+
+		if window%blockSize == blockSize-1 {
+			sig1[j] = b64[h1%64]
+			// The last character keeps absorbing pieces once full.
+			if j < len(sig1)-1 {
+				h1 = hashInit
+				pending1 = false
+				j++
+			}
+		}
+
+		if window%(blockSize*2) == blockSize*2-1 {
+			sig2[k] = b64[h2%64]
+			if k < len(sig2)-1 {
+				h2 = hashInit
+				pending2 = false
+				k++
+			}
+		}
+	}
+
+	// Flush the trailing partial pieces.
+	if pending1 {
+		sig1[j] = b64[h1%64]
+		j++
+	}
+	if pending2 {
+		sig2[k] = b64[h2%64]
+		k++
+	}
+
+	return string(sig1[:j]), string(sig2[:k])
+}
+
+type digest struct {
+	blockSize uint64
+	sig1      string
+	sig2      string
+}
+
+func parse(s string) (digest, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return digest{}, ErrInvalidDigest
+	}
+
+	blockSize, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || blockSize < MinBlockSize {
+		return digest{}, ErrInvalidDigest
+	}
+
+	if len(parts[1]) > SignatureLength || len(parts[2]) > SignatureLength {
+		return digest{}, ErrInvalidDigest
+	}
+
+	return digest{
+		blockSize: blockSize,
+		sig1:      eliminateSequences(parts[1]),
+		sig2:      eliminateSequences(parts[2]),
+	}, nil
+}
@@ -0,0 +1,210 @@
+package fuzzy
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden digests in testdata")
+
+// Deterministic pseudo-text so the golden digests don't depend on large
+// checked-in inputs.
+func corpus(seed int64, n int) []byte {
+	rng := rand.New(rand.NewSource(seed))
+	words := make([]string, 2000)
+	for i := range words {
+		w := make([]byte, 1+rng.Intn(9))
+		for j := range w {
+			w[j] = byte('a' + rng.Intn(26))
+		}
+		words[i] = string(w)
+	}
+
+	var sb strings.Builder
+	for sb.Len() < n {
+		sb.WriteString(words[rng.Intn(len(words))])
+		if rng.Intn(12) == 0 {
+			sb.WriteString(".\n")
+		} else {
+			sb.WriteByte(' ')
+		}
+	}
+	return []byte(sb.String()[:n])
+}
+
+func goldenInputs() map[string][]byte {
+	base := corpus(1, 64*1024)
+
+	edited := append([]byte(nil), base...)
+	copy(edited[30000:], "an edit in the middle of the input")
+
+	appended := append(append([]byte(nil), base...), corpus(2, 4096)...)
+
+	return map[string][]byte{
+		"empty":    nil,
+		"tiny":     []byte("abc"),
+		"short":    corpus(3, 1000),
+		"base":     base,
+		"edited":   edited,
+		"appended": appended,
+		"other":    corpus(4, 64*1024),
+	}
+}
+
+func TestGoldenDigests(t *testing.T) {
+	path := filepath.Join("testdata", "digests.txt")
+	inputs := goldenInputs()
+
+	if *update {
+		f, err := os.Create(path)
+		assert.NoError(t, err)
+		defer f.Close()
+		for _, name := range []string{"empty", "tiny", "short", "base", "edited", "appended", "other"} {
+			fmt.Fprintf(f, "%s %s\n", name, Digest(inputs[name]))
+		}
+		return
+	}
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	count := 0
+	for scanner.Scan() {
+		name, want, ok := strings.Cut(scanner.Text(), " ")
+		assert.True(t, ok)
+		assert.Equal(t, want, Digest(inputs[name]), "digest mismatch for %s", name)
+		count++
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, len(inputs), count)
+}
+
+// The golden digests above are this package's own output, so they only
+// catch regressions. Scores are checked against ssdeep itself.
+func TestCompareReferenceScores(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "scores.txt"))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	count := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		assert.Len(t, fields, 3)
+		want, err := strconv.Atoi(fields[0])
+		assert.NoError(t, err)
+
+		got, err := Compare(fields[1], fields[2])
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, "score for %s %s", fields[1], fields[2])
+		got, err = Compare(fields[2], fields[1])
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, "score for %s %s", fields[2], fields[1])
+		count++
+	}
+	assert.NoError(t, scanner.Err())
+	assert.NotZero(t, count)
+}
+
+func TestDigestFormat(t *testing.T) {
+	assert.Equal(t, "3::", Digest(nil))
+
+	d, err := parse(Digest(corpus(5, 100*1024)))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(d.sig1), SignatureLength/2)
+	assert.LessOrEqual(t, len(d.sig1), SignatureLength)
+	assert.LessOrEqual(t, len(d.sig2), SignatureLength/2)
+	assert.Zero(t, d.blockSize%MinBlockSize)
+}
+
+func TestCompareRelated(t *testing.T) {
+	inputs := goldenInputs()
+	base := Digest(inputs["base"])
+
+	score, err := Compare(base, base)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, score)
+
+	score, err = Compare(base, Digest(inputs["edited"]))
+	assert.NoError(t, err)
+	assert.Greater(t, score, 70)
+
+	score, err = Compare(base, Digest(inputs["appended"]))
+	assert.NoError(t, err)
+	assert.Greater(t, score, 50)
+
+	score, err = Compare(base, Digest(inputs["other"]))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, score)
+}
+
+func TestCompareScoring(t *testing.T) {
+	sig := "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	// Block sizes too far apart can't be compared.
+	score, err := Compare("48:"+sig+":", "192:"+sig+":")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, score)
+
+	// Adjacent block sizes compare sig1 against the other's sig2.
+	score, err = Compare("96:"+sig+":xyz", "48:qwe:"+sig)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, score)
+
+	// A substitution costs 2 over 72 characters, which scales down to 1.
+	score, err = Compare("48:"+sig+":", "48:"+strings.Replace(sig, "m", "M", 1)+":")
+	assert.NoError(t, err)
+	assert.Equal(t, 99, score)
+
+	// Ten substitutions cost 20, scaled to 17 of 64 and then to 26 percent.
+	score, err = Compare("48:"+sig+":", "48:"+strings.ToUpper(sig[:10])+sig[10:]+":")
+	assert.NoError(t, err)
+	assert.Equal(t, 74, score)
+
+	// Long runs are collapsed before comparing.
+	score, err = Compare("48:"+sig+"AAAAAAA:", "48:"+sig+"AAA:")
+	assert.NoError(t, err)
+	assert.Equal(t, 100, score)
+
+	// Small block sizes cap the score.
+	score, err = Compare("3:abcdefgh:", "3:abcdefgX:")
+	assert.NoError(t, err)
+	assert.Equal(t, 8, score)
+
+	// No common substring of RollingWindow characters.
+	score, err = Compare("48:abcdefghijkl:", "48:abcdefXhijkl:")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, score)
+}
+
+func TestCompareInvalid(t *testing.T) {
+	for _, bad := range []string{"", "3:abc", "x:abc:def", "1:abc:def", "3:" + strings.Repeat("a", 65) + ":"} {
+		_, err := Compare(bad, "3::")
+		assert.ErrorIs(t, err, ErrInvalidDigest, "digest %q", bad)
+		_, err = Compare("3::", bad)
+		assert.ErrorIs(t, err, ErrInvalidDigest, "digest %q", bad)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("abc", "abc"))
+	assert.Equal(t, 1, editDistance("abc", "ab"))
+	assert.Equal(t, 2, editDistance("abc", "abd"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, "abcccd", eliminateSequences("abccccccd"))
+}
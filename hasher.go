@@ -4,6 +4,175 @@ import "github.com/satmihir/buzhash/internal/hasher"
 
 type RollingHash = hasher.RollingHash
 
+type MatchRoller = hasher.MatchRoller
+
+type FuncRoller = hasher.FuncRoller
+
+type Cloner = hasher.Cloner
+
+var BulkRollMatch = hasher.BulkRollMatch
+
+var BulkRollFunc = hasher.BulkRollFunc
+
+var BulkRollContext = hasher.BulkRollContext
+
+type Hasher = hasher.Hasher
+
 var New = hasher.New
 
+var NewAppendable = hasher.NewAppendable
+
 var Hash = hasher.Hash
+
+type Option = hasher.Option
+
+var NewWithOptions = hasher.NewWithOptions
+
+var (
+	WithTable      = hasher.WithTable
+	WithSeed       = hasher.WithSeed
+	WithCompat     = hasher.WithCompat
+	WithBackend    = hasher.WithBackend
+	WithOutputBits = hasher.WithOutputBits
+)
+
+var NewString = hasher.NewString
+
+var HashString = hasher.HashString
+
+type File = hasher.File
+
+var OpenFile = hasher.OpenFile
+
+type PagedHasher = hasher.PagedHasher
+
+type PagedOptions = hasher.PagedOptions
+
+var NewPaged = hasher.NewPaged
+
+type SpacedSeed = hasher.SpacedSeed
+
+var ParseSpacedSeed = hasher.ParseSpacedSeed
+
+var NewSpaced = hasher.NewSpaced
+
+var BulkRollSeeds = hasher.BulkRollSeeds
+
+type HashIndex = hasher.HashIndex
+
+var NewHashIndex = hasher.NewHashIndex
+
+var Combine = hasher.Combine
+
+var StripPrefix = hasher.StripPrefix
+
+var StripSuffix = hasher.StripSuffix
+
+var NewSegmented = hasher.NewSegmented
+
+type MutableHasher = hasher.MutableHasher
+
+var NewMutable = hasher.NewMutable
+
+type Backend = hasher.Backend
+
+const (
+	BackendGo  = hasher.BackendGo
+	BackendCgo = hasher.BackendCgo
+)
+
+var Backends = hasher.Backends
+
+var LookupBackend = hasher.LookupBackend
+
+var DefaultBackend = hasher.DefaultBackend
+
+var SetDefaultBackend = hasher.SetDefaultBackend
+
+var NewVerifyBackend = hasher.NewVerifyBackend
+
+type Table = hasher.Table
+
+type TableVersion = hasher.TableVersion
+
+const (
+	TableV1     = hasher.TableV1
+	TableSeeded = hasher.TableSeeded
+)
+
+var Tables = hasher.Tables
+
+var LookupTable = hasher.LookupTable
+
+type Compat = hasher.Compat
+
+type CompatHasher = hasher.CompatHasher
+
+var (
+	CompatRollinghash64 = hasher.CompatRollinghash64
+	CompatRollinghash32 = hasher.CompatRollinghash32
+	CompatSilvasur      = hasher.CompatSilvasur
+)
+
+var (
+	ErrNotWritable     = hasher.ErrNotWritable
+	ErrWindowTooLong   = hasher.ErrWindowTooLong
+	ErrIllegalRoll     = hasher.ErrIllegalRoll
+	ErrIllegalStride   = hasher.ErrIllegalStride
+	ErrIllegalSeed     = hasher.ErrIllegalSeed
+	ErrIllegalRange    = hasher.ErrIllegalRange
+	ErrUnknownBackend  = hasher.ErrUnknownBackend
+	ErrBackendMismatch = hasher.ErrBackendMismatch
+	ErrInvalidUTF8     = hasher.ErrInvalidUTF8
+	ErrFileTooLarge    = hasher.ErrFileTooLarge
+	ErrIllegalSeek     = hasher.ErrIllegalSeek
+	ErrIllegalPaging   = hasher.ErrIllegalPaging
+	ErrIllegalEdit     = hasher.ErrIllegalEdit
+	ErrUnknownTable    = hasher.ErrUnknownTable
+	ErrIllegalOption   = hasher.ErrIllegalOption
+)
+
+type RuneHasher = hasher.RuneHasher
+
+type RuneOptions = hasher.RuneOptions
+
+type RuneWindow = hasher.RuneWindow
+
+type InvalidUTF8 = hasher.InvalidUTF8
+
+const (
+	ReplaceInvalid = hasher.ReplaceInvalid
+	RejectInvalid  = hasher.RejectInvalid
+)
+
+var NewRunes = hasher.NewRunes
+
+var HashRunes = hasher.HashRunes
+
+// Symbol is the set of integer types NewSymbols can roll over.
+type Symbol = hasher.Symbol
+
+// NewSymbols creates a rolling hasher over integer symbols such as token IDs.
+// A nil mapper defaults to SeededMapper with seed 0.
+func NewSymbols[T Symbol](syms []T, windowSize uint32, mapper func(T) uint64) (RollingHash, error) {
+	h, err := hasher.NewSymbols(syms, windowSize, mapper)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// HashSymbols hashes the symbols in one shot without rolling.
+func HashSymbols[T Symbol](syms []T, mapper func(T) uint64) uint64 {
+	return hasher.HashSymbols(syms, mapper)
+}
+
+// SeededMapper maps symbols to 64-bit values with a seeded mixer.
+func SeededMapper[T Symbol](seed uint64) func(T) uint64 {
+	return hasher.SeededMapper[T](seed)
+}
+
+// TableMapper maps every symbol to its entry in the table.
+func TableMapper[T Symbol](table []uint64) func(T) uint64 {
+	return hasher.TableMapper[T](table)
+}
@@ -0,0 +1,154 @@
+package hasher
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendMatchesConcatenation(t *testing.T) {
+	rng := rand.New(rand.NewSource(17))
+	data := make([]byte, 5000)
+	rng.Read(data)
+	windowSize := uint32(6)
+
+	first := data[:100:100]
+	hasher := NewAppendable(first, windowSize)
+	h := RollingHash(hasher)
+
+	fed := len(first)
+	for pos := uint32(0); pos+windowSize <= uint32(len(data)); pos++ {
+		// Feed the tailer whenever it runs dry, in pieces of varying size.
+		for fed < len(data) && int(pos+windowSize) >= fed {
+			n := min(1+rng.Intn(64), len(data)-fed)
+			hasher.Append(data[fed : fed+n])
+			fed += n
+		}
+
+		if pos > 0 {
+			got, err := h.Roll(1)
+			assert.NoError(t, err)
+			assert.Equal(t, Hash(data[pos:pos+windowSize]), got, "mismatch at offset %d", pos)
+		}
+		assert.Equal(t, pos, h.Position())
+
+		// Dropping the consumed input keeps the memory bounded by the
+		// unread input.
+		hasher.Discard()
+		assert.Equal(t, pos, hasher.offset)
+		assert.Equal(t, fed-int(pos), len(hasher.buf))
+		assert.Less(t, cap(hasher.buf), 1024)
+	}
+
+	_, err := h.Roll(1)
+	assert.ErrorIs(t, err, ErrIllegalRoll)
+}
+
+func TestAppendBulkRoll(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	windowSize := uint32(4)
+
+	h, err := New(data[:10], windowSize)
+	assert.NoError(t, err)
+	_, err = h.Roll(5)
+	assert.NoError(t, err)
+
+	hasher := h.(*Hasher)
+	hasher.Append(data[10:30])
+	_, err = h.Roll(15)
+	assert.NoError(t, err)
+	hasher.Append(nil)
+	hasher.Append(data[30:])
+	assert.Equal(t, uint32(20), h.Position())
+
+	hashes, err := h.BulkRoll(2)
+	assert.NoError(t, err)
+	var expected []uint64
+	for i := 20; i+int(windowSize) <= len(data); i += 2 {
+		expected = append(expected, Hash(data[i:i+int(windowSize)]))
+	}
+	assert.Equal(t, expected, hashes)
+
+	// Positions reported by the bulk APIs are global too.
+	positions, _, err := BulkRollMatch(h, 0, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(20), positions[0])
+
+	err = BulkRollFunc(h, 1, func(pos uint32, hash uint64) bool {
+		assert.Equal(t, Hash(data[pos:pos+windowSize]), hash)
+		return true
+	})
+	assert.NoError(t, err)
+
+	// Append keeps all input, so Reset goes back to the start.
+	h.Reset()
+	assert.Equal(t, uint32(0), h.Position())
+	assert.Equal(t, Hash(data[:windowSize]), h.Sum64())
+
+	// After Discard it goes back to the oldest retained byte.
+	_, err = h.Roll(20)
+	assert.NoError(t, err)
+	_, err = h.Roll(3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(23), hasher.Discard())
+	assert.Equal(t, uint32(0), hasher.Discard())
+	h.Reset()
+	assert.Equal(t, uint32(23), h.Position())
+	assert.Equal(t, Hash(data[23:23+windowSize]), h.Sum64())
+}
+
+func TestAppendableStartsShort(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	windowSize := uint32(8)
+
+	h := NewAppendable(nil, windowSize)
+	for _, piece := range [][]byte{data[:3], nil, data[3:7]} {
+		h.Append(piece)
+
+		// No window yet.
+		assert.Equal(t, uint64(0), h.Sum64())
+		_, err := h.Roll(1)
+		assert.ErrorIs(t, err, ErrIllegalRoll)
+		hashes, err := h.BulkRoll(1)
+		assert.NoError(t, err)
+		assert.Empty(t, hashes)
+		positions, _, err := h.BulkRollMatch(0, 0, 1)
+		assert.NoError(t, err)
+		assert.Empty(t, positions)
+		assert.NoError(t, h.BulkRollFunc(1, func(uint32, uint64) bool {
+			t.Fatal("visited a window of a short input")
+			return false
+		}))
+		assert.Equal(t, uint32(0), h.Discard())
+		h.Reset()
+		assert.Equal(t, uint32(0), h.Position())
+	}
+
+	h.Append(data[7:12])
+	assert.Equal(t, Hash(data[:windowSize]), h.Sum64())
+	hash, err := h.Roll(4)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(data[4:12]), hash)
+
+	h.Append(data[12:])
+	hashes, err := h.BulkRoll(1)
+	assert.NoError(t, err)
+	assert.Len(t, hashes, len(data)-int(windowSize)-3)
+	assert.Equal(t, Hash(data[len(data)-int(windowSize):]), hashes[len(hashes)-1])
+}
+
+func TestAppendDoesNotWriteCallerBuffer(t *testing.T) {
+	backing := []byte("abcdefgh")
+	orig := append([]byte(nil), backing...)
+
+	h, err := New(backing[:4], 2)
+	assert.NoError(t, err)
+	h.(*Hasher).Append([]byte("XYZW"))
+
+	assert.Equal(t, orig, backing)
+
+	hashes, err := h.BulkRoll(1)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash([]byte("dX")), hashes[3])
+}
@@ -0,0 +1,165 @@
+package hasher
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// The pure Go loops, available in every build.
+	BackendGo = "go"
+	// The C loops, available when built with cgo.
+	BackendCgo = "cgo"
+)
+
+var ErrUnknownBackend = errors.New("unknown backend")
+var ErrBackendMismatch = errors.New("backends returned different results")
+
+// A Backend implements the bulk loops behind Hasher.BulkRoll and
+// Hasher.BulkRollMatch. Every backend must return exactly the same results;
+// they only differ in speed. Roll and the callback-driven bulk methods
+// always run in Go.
+type Backend struct {
+	// The name the backend is registered under
+	name string
+	// Implements Hasher.BulkRoll
+	bulkRoll func(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash uint64) ([]uint64, error)
+	// Implements Hasher.BulkRollMatch
+	bulkRollMatch func(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash, mask, target uint64) ([]uint32, []uint64, error)
+}
+
+// Name returns the name of the backend.
+func (b *Backend) Name() string {
+	return b.name
+}
+
+var goBackend = &Backend{
+	name: BackendGo,
+	bulkRoll: func(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash uint64) ([]uint64, error) {
+		return bulkRollGo(t, buf, start, windowSize, stride, initialHash), nil
+	},
+	bulkRollMatch: func(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash, mask, target uint64) ([]uint32, []uint64, error) {
+		positions, hashes := bulkRollMatchGo(t, buf, start, windowSize, stride, initialHash, mask, target)
+		return positions, hashes, nil
+	},
+}
+
+var (
+	// Guards backends
+	backendsMu sync.RWMutex
+	// All compiled-in backends by name
+	backends = map[string]*Backend{BackendGo: goBackend}
+	// The backend used by hashers without their own
+	defaultBackend atomic.Pointer[Backend]
+)
+
+func init() {
+	if defaultBackend.Load() == nil {
+		defaultBackend.Store(goBackend)
+	}
+}
+
+// Makes a compiled-in backend available by name. Native backends call this
+// from an init function in their build-tagged file.
+func registerBackend(b *Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[b.name] = b
+}
+
+// Backends returns the names of all backends available in this build.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// LookupBackend returns the backend registered under the given name.
+func LookupBackend(name string) (*Backend, error) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	b, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBackend, name)
+	}
+
+	return b, nil
+}
+
+// DefaultBackend returns the backend used by hashers that have not been
+// given one. It is the cgo backend when compiled in and Go otherwise.
+func DefaultBackend() *Backend {
+	return defaultBackend.Load()
+}
+
+// SetDefaultBackend switches the backend for every hasher of the process
+// that has not been given its own. It is safe to call concurrently with
+// hashing; bulk calls already running finish on the previous backend.
+func SetDefaultBackend(b *Backend) {
+	if b == nil {
+		panic("buzhash: nil backend")
+	}
+	defaultBackend.Store(b)
+}
+
+// NewVerifyBackend returns a backend that runs both primary and secondary on
+// every call and compares their results, for canary deployments. On any
+// divergence it panics if panicOnMismatch is set and otherwise fails the call
+// with ErrBackendMismatch. The results of primary are returned.
+func NewVerifyBackend(primary, secondary *Backend, panicOnMismatch bool) *Backend {
+	name := fmt.Sprintf("verify(%s,%s)", primary.name, secondary.name)
+
+	mismatch := func(call string) error {
+		err := fmt.Errorf("%w: %s in %s", ErrBackendMismatch, call, name)
+		if panicOnMismatch {
+			panic(err)
+		}
+		return err
+	}
+
+	return &Backend{
+		name: name,
+		bulkRoll: func(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash uint64) ([]uint64, error) {
+			want, err := primary.bulkRoll(t, buf, start, windowSize, stride, initialHash)
+			if err != nil {
+				return nil, err
+			}
+			got, err := secondary.bulkRoll(t, buf, start, windowSize, stride, initialHash)
+			if err != nil {
+				return nil, err
+			}
+
+			if !slices.Equal(want, got) {
+				return nil, mismatch("BulkRoll")
+			}
+			return want, nil
+		},
+		bulkRollMatch: func(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash, mask, target uint64) ([]uint32, []uint64, error) {
+			wantPos, wantHashes, err := primary.bulkRollMatch(t, buf, start, windowSize, stride, initialHash, mask, target)
+			if err != nil {
+				return nil, nil, err
+			}
+			gotPos, gotHashes, err := secondary.bulkRollMatch(t, buf, start, windowSize, stride, initialHash, mask, target)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if !slices.Equal(wantPos, gotPos) || !slices.Equal(wantHashes, gotHashes) {
+				return nil, nil, mismatch("BulkRollMatch")
+			}
+			return wantPos, wantHashes, nil
+		},
+	}
+}
@@ -0,0 +1,171 @@
+package hasher
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// All compiled-in backends.
+func availableBackends(t testing.TB) []*Backend {
+	var out []*Backend
+	for _, name := range Backends() {
+		b, err := LookupBackend(name)
+		assert.NoError(t, err)
+		out = append(out, b)
+	}
+	return out
+}
+
+func TestBackendRegistry(t *testing.T) {
+	names := Backends()
+	assert.Contains(t, names, BackendGo)
+
+	b, err := LookupBackend(BackendGo)
+	assert.NoError(t, err)
+	assert.Equal(t, BackendGo, b.Name())
+
+	_, err = LookupBackend("simd")
+	assert.ErrorIs(t, err, ErrUnknownBackend)
+
+	// The cgo loops are the default whenever they are compiled in.
+	if _, err := LookupBackend(BackendCgo); err == nil {
+		assert.Equal(t, BackendCgo, DefaultBackend().Name())
+	} else {
+		assert.Equal(t, BackendGo, DefaultBackend().Name())
+	}
+}
+
+func TestBackendsAgree(t *testing.T) {
+	rng := rand.New(rand.NewSource(31))
+	backends := availableBackends(t)
+
+	for trial := 0; trial < 100; trial++ {
+		data := make([]byte, rng.Intn(300))
+		rng.Read(data)
+		windowSize := uint32(rng.Intn(len(data) + 1))
+		if trial%10 == 0 {
+			// Windows longer than a rotation cycle.
+			data = append(data, make([]byte, 100)...)
+			windowSize = uint32(64 + rng.Intn(30))
+		}
+		stride := uint32(1 + rng.Intn(4))
+		start := uint32(rng.Intn(len(data) - int(windowSize) + 1))
+		hash := Hash(data[start : start+windowSize])
+
+		want, _ := goBackend.bulkRoll(&table, data, start, windowSize, stride, hash)
+		wantPos, wantHashes, _ := goBackend.bulkRollMatch(&table, data, start, windowSize, stride, hash, 0x3, 0x2)
+
+		for _, b := range backends {
+			got, err := b.bulkRoll(&table, data, start, windowSize, stride, hash)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got, "backend %s window %d", b.Name(), windowSize)
+
+			gotPos, gotHashes, err := b.bulkRollMatch(&table, data, start, windowSize, stride, hash, 0x3, 0x2)
+			assert.NoError(t, err)
+			assert.Equal(t, wantPos, gotPos, "backend %s window %d", b.Name(), windowSize)
+			assert.Equal(t, wantHashes, gotHashes, "backend %s window %d", b.Name(), windowSize)
+		}
+	}
+}
+
+func TestBackendsRotationWrap(t *testing.T) {
+	data := make([]byte, 400)
+	rand.New(rand.NewSource(37)).Read(data)
+
+	// Rotations by the window size wrap at 64 bits, where shifting by
+	// window and 64-window in C is undefined.
+	for _, windowSize := range []uint32{63, 64, 65, 127, 128, 129, 200} {
+		var want []uint64
+		for i := uint32(0); i+windowSize <= uint32(len(data)); i++ {
+			want = append(want, Hash(data[i:i+windowSize]))
+		}
+
+		for _, b := range availableBackends(t) {
+			got, err := b.bulkRoll(&table, data, 0, windowSize, 1, want[0])
+			assert.NoError(t, err)
+			assert.Equal(t, want, got, "backend %s window %d", b.Name(), windowSize)
+		}
+	}
+}
+
+func TestEmptyInputOnAllBackends(t *testing.T) {
+	for _, b := range availableBackends(t) {
+		h, err := New(nil, 0)
+		assert.NoError(t, err)
+		h.(*Hasher).SetBackend(b)
+
+		hashes, err := h.BulkRoll(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{0}, hashes, "backend %s", b.Name())
+
+		positions, _, err := BulkRollMatch(h, 0, 0, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint32{0}, positions, "backend %s", b.Name())
+	}
+}
+
+func TestHasherBackendSelection(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	h, err := New(data, 4)
+	assert.NoError(t, err)
+	hasher := h.(*Hasher)
+
+	assert.Equal(t, DefaultBackend(), hasher.Backend())
+
+	hasher.SetBackend(goBackend)
+	assert.Equal(t, BackendGo, hasher.Backend().Name())
+	want, err := h.BulkRoll(1)
+	assert.NoError(t, err)
+
+	// The process default only applies to hashers without their own.
+	prev := DefaultBackend()
+	defer SetDefaultBackend(prev)
+	verify := NewVerifyBackend(goBackend, prev, true)
+	SetDefaultBackend(verify)
+	assert.Equal(t, BackendGo, hasher.Backend().Name())
+
+	hasher.SetBackend(nil)
+	assert.Equal(t, verify, hasher.Backend())
+	got, err := h.BulkRoll(1)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	assert.Panics(t, func() { SetDefaultBackend(nil) })
+}
+
+func TestVerifyBackendMismatch(t *testing.T) {
+	broken := &Backend{
+		name: "broken",
+		bulkRoll: func(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash uint64) ([]uint64, error) {
+			hashes := bulkRollGo(t, buf, start, windowSize, stride, initialHash)
+			hashes[len(hashes)-1] ^= 1
+			return hashes, nil
+		},
+		bulkRollMatch: func(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash, mask, target uint64) ([]uint32, []uint64, error) {
+			return nil, nil, nil
+		},
+	}
+
+	data := []byte("abcdefghijklmnop")
+	h, err := New(data, 3)
+	assert.NoError(t, err)
+	hasher := h.(*Hasher)
+
+	hasher.SetBackend(NewVerifyBackend(goBackend, broken, false))
+	assert.Equal(t, "verify(go,broken)", hasher.Backend().Name())
+
+	_, err = h.BulkRoll(1)
+	assert.ErrorIs(t, err, ErrBackendMismatch)
+	_, _, err = BulkRollMatch(h, 0, 0, 1)
+	assert.ErrorIs(t, err, ErrBackendMismatch)
+
+	hasher.SetBackend(NewVerifyBackend(goBackend, broken, true))
+	assert.Panics(t, func() { _, _ = h.BulkRoll(1) })
+
+	// A verify backend over identical loops never fires.
+	hasher.SetBackend(NewVerifyBackend(goBackend, goBackend, true))
+	_, err = h.BulkRoll(2)
+	assert.NoError(t, err)
+}
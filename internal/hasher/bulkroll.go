@@ -1,11 +1,9 @@
-//go:build !cgo
-// +build !cgo
-
 package hasher
 
 import "math/bits"
 
-func bulkRoll(buf []byte, start, windowSize, stride uint32, initialHash uint64) []uint64 {
+// The pure Go bulk loops, always available as the "go" backend.
+func bulkRollGo(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash uint64) []uint64 {
 	n := uint32(len(buf))
 	capacity := (n-windowSize-start)/stride + 1
 	hashes := make([]uint64, 0, capacity)
@@ -28,8 +26,8 @@ func bulkRoll(buf []byte, start, windowSize, stride uint32, initialHash uint64)
 			in := buf[pos+windowSize]
 
 			hash = bits.RotateLeft64(hash, 1) ^
-				bits.RotateLeft64(table[out], int(windowSize)) ^
-				table[in]
+				bits.RotateLeft64(t[out], int(windowSize)) ^
+				t[in]
 
 			pos++
 		}
@@ -37,3 +35,43 @@ func bulkRoll(buf []byte, start, windowSize, stride uint32, initialHash uint64)
 
 	return hashes
 }
+
+// Filtering only pays off over bulkRollGo if the roll itself is tight, so
+// the rotated values of outgoing bytes are computed once per call and the
+// loop steps one byte at a time, checking a window only every stride bytes.
+func bulkRollMatchGo(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash, mask, target uint64) ([]uint32, []uint64) {
+	n := uint32(len(buf))
+	if start+windowSize > n {
+		return nil, nil
+	}
+
+	var rotated [256]uint64
+	for i, v := range t {
+		rotated[i] = bits.RotateLeft64(v, int(windowSize))
+	}
+
+	// Window i drops outgoing[i] and takes in incoming[i] when rolled.
+	last := n - windowSize
+	outgoing := buf[:last]
+	incoming := buf[windowSize:]
+	incoming = incoming[:len(outgoing)]
+
+	var positions []uint32
+	var hashes []uint64
+	hash := initialHash
+
+	next := start
+	for pos := start; ; pos++ {
+		if pos == next {
+			if hash&mask == target {
+				positions = append(positions, pos)
+				hashes = append(hashes, hash)
+			}
+			if last-pos < stride {
+				return positions, hashes
+			}
+			next += stride
+		}
+		hash = bits.RotateLeft64(hash, 1) ^ rotated[outgoing[pos]] ^ t[incoming[pos]]
+	}
+}
@@ -0,0 +1,51 @@
+package hasher
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Chunking-style parameters: a 48 byte window and an 8 KiB average chunk.
+const (
+	benchSize   = 8 << 20
+	benchWindow = 48
+	benchMask   = 0x1fff
+)
+
+func benchData() []byte {
+	data := make([]byte, benchSize)
+	rand.New(rand.NewSource(43)).Read(data)
+	return data
+}
+
+func BenchmarkBulkRoll(b *testing.B) {
+	data := benchData()
+	hash := Hash(data[:benchWindow])
+
+	for _, backend := range availableBackends(b) {
+		b.Run(backend.Name(), func(b *testing.B) {
+			b.SetBytes(benchSize)
+			for i := 0; i < b.N; i++ {
+				if _, err := backend.bulkRoll(&table, data, 0, benchWindow, 1, hash); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBulkRollMatch(b *testing.B) {
+	data := benchData()
+	hash := Hash(data[:benchWindow])
+
+	for _, backend := range availableBackends(b) {
+		b.Run(backend.Name(), func(b *testing.B) {
+			b.SetBytes(benchSize)
+			for i := 0; i < b.N; i++ {
+				if _, _, err := backend.bulkRollMatch(&table, data, 0, benchWindow, 1, hash, benchMask, 0); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
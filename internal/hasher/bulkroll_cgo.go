@@ -6,6 +6,14 @@ package hasher
 /*
 #include <stdint.h>
 
+// Rotates left by r modulo 64 without undefined shifts. Shifting a uint64_t
+// by 64 or more is undefined in C, which windows of 64 bytes and longer hit
+// when rotating the outgoing byte by the window size.
+static inline uint64_t buz_rotl(uint64_t x, int r) {
+	r &= 63;
+	return (x << r) | (x >> ((64 - r) & 63));
+}
+
 void buz_bulk_roll(uint8_t* buf, int len, int start, int window, int stride, uint64_t hash, const uint64_t* table, uint64_t* out) {
 	int pos = start;
 	int count = 0;
@@ -19,22 +27,91 @@ void buz_bulk_roll(uint8_t* buf, int len, int start, int window, int stride, uin
 			}
 			uint64_t outByte = table[buf[pos]];
 			uint64_t inByte = table[buf[pos + window]];
-			hash = (hash << 1 | hash >> 63) ^ (outByte << window | outByte >> (64 - window)) ^ inByte;
+			hash = buz_rotl(hash, 1) ^ buz_rotl(outByte, window) ^ inByte;
 			pos++;
 		}
 	}
 }
+
+// Like buz_bulk_roll but only records windows where (hash & mask) == target.
+// Stops once cap matches are recorded, leaving *start and *hash at the next
+// window to examine so the caller can resume with more room. Sets *done once
+// the buffer is exhausted.
+int buz_bulk_roll_match(uint8_t* buf, int len, int* start, int window, int stride, uint64_t* hash, const uint64_t* table,
+		uint64_t mask, uint64_t target, uint32_t* outPos, uint64_t* outHash, int cap, int* done) {
+	int pos = *start;
+	uint64_t h = *hash;
+	int count = 0;
+
+	*done = 0;
+	while (pos + window <= len) {
+		if ((h & mask) == target) {
+			if (count == cap) {
+				*start = pos;
+				*hash = h;
+				return count;
+			}
+			outPos[count] = (uint32_t)pos;
+			outHash[count] = h;
+			count++;
+		}
+
+		for (int i = 0; i < stride; i++) {
+			if (pos + window >= len) {
+				*done = 1;
+				return count;
+			}
+			uint64_t outByte = table[buf[pos]];
+			uint64_t inByte = table[buf[pos + window]];
+			h = buz_rotl(h, 1) ^ buz_rotl(outByte, window) ^ inByte;
+			pos++;
+		}
+	}
+
+	*done = 1;
+	return count;
+}
 */
 import "C"
 import (
+	"math"
 	"unsafe"
 )
 
-func bulkRoll(buf []byte, start, windowSize, stride uint32, initialHash uint64) []uint64 {
+// The initial room for matches, grown as needed.
+const matchChunk = 64
+
+// The longest buffer the C loops can address with an int. Longer buffers,
+// which files between 2 and 4 GiB produce, fall back to the Go loops. A
+// variable so tests can force the fallback without allocating 2 GiB.
+var cgoMaxLen = math.MaxInt32
+
+var cgoBackend = &Backend{
+	name: BackendCgo,
+	bulkRoll: func(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash uint64) ([]uint64, error) {
+		return bulkRollCgo(t, buf, start, windowSize, stride, initialHash), nil
+	},
+	bulkRollMatch: func(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash, mask, target uint64) ([]uint32, []uint64, error) {
+		positions, hashes := bulkRollMatchCgo(t, buf, start, windowSize, stride, initialHash, mask, target)
+		return positions, hashes, nil
+	},
+}
+
+// The cgo loops are preferred whenever they are compiled in.
+func init() {
+	registerBackend(cgoBackend)
+	defaultBackend.Store(cgoBackend)
+}
+
+func bulkRollCgo(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash uint64) []uint64 {
 	n := uint32(len(buf))
-	if stride == 0 || windowSize == 0 || start+windowSize > n {
+	if start+windowSize > n {
 		return nil
 	}
+	// Empty windows leave nothing for C to address, fall back to Go.
+	if windowSize == 0 || len(buf) > cgoMaxLen {
+		return bulkRollGo(t, buf, start, windowSize, stride, initialHash)
+	}
 
 	capacity := (n-windowSize-start)/stride + 1
 	hashes := make([]uint64, capacity)
@@ -46,9 +123,59 @@ func bulkRoll(buf []byte, start, windowSize, stride uint32, initialHash uint64)
 		C.int(windowSize),
 		C.int(stride),
 		C.uint64_t(initialHash),
-		(*C.uint64_t)(unsafe.Pointer(&table)),
+		(*C.uint64_t)(unsafe.Pointer(t)),
 		(*C.uint64_t)(unsafe.Pointer(&hashes[0])),
 	)
 
 	return hashes
 }
+
+func bulkRollMatchCgo(t *[256]uint64, buf []byte, start, windowSize, stride uint32, initialHash, mask, target uint64) ([]uint32, []uint64) {
+	n := uint32(len(buf))
+	if start+windowSize > n {
+		return nil, nil
+	}
+	if windowSize == 0 || len(buf) > cgoMaxLen {
+		return bulkRollMatchGo(t, buf, start, windowSize, stride, initialHash, mask, target)
+	}
+
+	positions := make([]uint32, matchChunk)
+	hashes := make([]uint64, matchChunk)
+	count := 0
+
+	pos := C.int(start)
+	hash := C.uint64_t(initialHash)
+	var done C.int
+
+	for {
+		count += int(C.buz_bulk_roll_match(
+			(*C.uint8_t)(unsafe.Pointer(&buf[0])),
+			C.int(len(buf)),
+			&pos,
+			C.int(windowSize),
+			C.int(stride),
+			&hash,
+			(*C.uint64_t)(unsafe.Pointer(t)),
+			C.uint64_t(mask),
+			C.uint64_t(target),
+			(*C.uint32_t)(unsafe.Pointer(&positions[count])),
+			(*C.uint64_t)(unsafe.Pointer(&hashes[count])),
+			C.int(len(positions)-count),
+			&done,
+		))
+
+		if done != 0 {
+			break
+		}
+
+		// Out of room, double it and resume where the loop stopped.
+		positions = append(positions, make([]uint32, len(positions))...)
+		hashes = append(hashes, make([]uint64, len(hashes))...)
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	return positions[:count], hashes[:count]
+}
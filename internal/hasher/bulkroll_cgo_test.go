@@ -0,0 +1,35 @@
+//go:build cgo
+// +build cgo
+
+package hasher
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCgoLongBufferFallback(t *testing.T) {
+	data := make([]byte, 400)
+	rand.New(rand.NewSource(41)).Read(data)
+
+	want, _ := goBackend.bulkRoll(&table, data, 0, 16, 3, Hash(data[:16]))
+	wantPos, wantHashes, _ := goBackend.bulkRollMatch(&table, data, 0, 16, 3, Hash(data[:16]), 0x3, 0x1)
+
+	// Buffers past what a C int indexes have to roll in Go rather than
+	// wrap the length negative and return zeroes.
+	prev := cgoMaxLen
+	defer func() { cgoMaxLen = prev }()
+	cgoMaxLen = len(data) - 1
+
+	got, err := cgoBackend.bulkRoll(&table, data, 0, 16, 3, Hash(data[:16]))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	gotPos, gotHashes, err := cgoBackend.bulkRollMatch(&table, data, 0, 16, 3, Hash(data[:16]), 0x3, 0x1)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, gotPos)
+	assert.Equal(t, wantPos, gotPos)
+	assert.Equal(t, wantHashes, gotHashes)
+}
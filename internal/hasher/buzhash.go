@@ -1,14 +1,18 @@
 package hasher
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"hash"
 	"math/bits"
+	"sync/atomic"
 )
 
 const (
 	hashSizeBytes = 8 // 64 bits = 8 bytes
+	// How many bytes BulkRollContext rolls over between checks of ctx.Done()
+	contextCheckInterval = 1 << 14
 )
 
 var ErrNotWritable = errors.New("this hasher is not writable")
@@ -81,22 +85,131 @@ type RollingHash interface {
 	Position() uint32
 }
 
+// A RollingHash that filters windows inside its bulk loop. The
+// BulkRollMatch function falls back to BulkRoll for other hashers.
+type MatchRoller interface {
+	RollingHash
+	// Rolls over the window at the given stride like BulkRoll but only returns
+	// the positions and hashes of the windows where hash&mask == target.
+	// Does not change the window starting position.
+	BulkRollMatch(mask, target uint64, stride uint32) ([]uint32, []uint64, error)
+}
+
+// A RollingHash that streams windows to a callback without materializing
+// them. The BulkRollFunc and BulkRollContext functions fall back to BulkRoll
+// for other hashers.
+type FuncRoller interface {
+	RollingHash
+	// Rolls over the window at the given stride and calls fn with the position
+	// and hash of every window until fn returns false.
+	// Does not change the window starting position.
+	BulkRollFunc(stride uint32, fn func(pos uint32, h uint64) bool) error
+	// Like BulkRollFunc but also stops and returns ctx.Err() once ctx is done.
+	// Does not change the window starting position.
+	BulkRollContext(ctx context.Context, stride uint32, fn func(pos uint32, h uint64) bool) error
+}
+
+// A RollingHash that can be forked at its current position.
+type Cloner interface {
+	RollingHash
+	// Returns an independent hasher at the same position and hash, sharing
+	// the immutable input.
+	Clone() RollingHash
+}
+
+// BulkRollMatch returns the positions and hashes of the windows of h where
+// hash&mask == target, like MatchRoller. Hashers that do not implement
+// MatchRoller are filtered over the output of BulkRoll.
+func BulkRollMatch(h RollingHash, mask, target uint64, stride uint32) ([]uint32, []uint64, error) {
+	if m, ok := h.(MatchRoller); ok {
+		return m.BulkRollMatch(mask, target, stride)
+	}
+
+	all, err := h.BulkRoll(stride)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var positions []uint32
+	var hashes []uint64
+	for i, hash := range all {
+		if hash&mask == target {
+			positions = append(positions, h.Position()+uint32(i)*stride)
+			hashes = append(hashes, hash)
+		}
+	}
+
+	return positions, hashes, nil
+}
+
+// BulkRollFunc calls fn with the position and hash of every window of h
+// until fn returns false, like FuncRoller. Hashers that do not implement
+// FuncRoller are visited over the output of BulkRoll.
+func BulkRollFunc(h RollingHash, stride uint32, fn func(pos uint32, h uint64) bool) error {
+	if f, ok := h.(FuncRoller); ok {
+		return f.BulkRollFunc(stride, fn)
+	}
+
+	all, err := h.BulkRoll(stride)
+	if err != nil {
+		return err
+	}
+
+	for i, hash := range all {
+		if !fn(h.Position()+uint32(i)*stride, hash) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// BulkRollContext is BulkRollFunc that also stops and returns ctx.Err() once
+// ctx is done. Hashers that do not implement FuncRoller run their whole
+// BulkRoll before the first window is visited, so cancellation only stops
+// the calls to fn after it and cannot interrupt the roll itself.
+func BulkRollContext(ctx context.Context, h RollingHash, stride uint32, fn func(pos uint32, h uint64) bool) error {
+	if f, ok := h.(FuncRoller); ok {
+		return f.BulkRollContext(ctx, stride, fn)
+	}
+
+	return bulkRollContext(ctx, stride, fn, func(stride uint32, fn func(pos uint32, h uint64) bool) error {
+		return BulkRollFunc(h, stride, fn)
+	})
+}
+
 // Implements RollingHash to calculate hashes rolling over a fixed buffer
 // in steps or in bulk for better performance.
 // Also implements hashing.Hash64 interface for interop but BEWARE that
 // this is NOT a streaming hash and does not implement the incremental
 // Write([]byte) method. The buffer has to be presented when constructing
-// the object and can never be mutated. Reset() method will simply zero
-// out all the state and make this object useless.
+// the object and can never be mutated, only extended with Append. Reset()
+// method will simply zero out all the state and make this object useless.
+//
+// A Hasher from NewAppendable may start with less input than a window. Until
+// Append completes the first window it has no windows to roll over: Sum64
+// returns 0, Roll fails with ErrIllegalRoll and the bulk methods report
+// nothing.
 type Hasher struct {
 	// The inner immutable buffer to hash over
 	buf []byte
 	// The window size for calculating the hash
 	windowSize uint32
-	// The current window start position
+	// The current window start position within buf
 	position uint32
 	// The current pre-computed hash
 	hash uint64
+	// The number of input bytes dropped from the front of buf by Append
+	offset uint32
+	// The write end of buf when it was allocated by Append, shared with
+	// clones, nil when buf belongs to the caller
+	tail *appendTail
+	// The backend for bulk loops, nil to follow DefaultBackend
+	backend *Backend
+	// The table of byte values, nil for TableV1
+	table *Table
+	// The mask applied to every returned hash, 0 to return all 64 bits
+	outMask uint64
 }
 
 // BulkRoll implements RollingHash.
@@ -104,8 +217,129 @@ func (h *Hasher) BulkRoll(stride uint32) ([]uint64, error) {
 	if stride == 0 {
 		return nil, ErrIllegalStride
 	}
+	if h.pending() {
+		return nil, nil
+	}
+
+	hashes, err := h.Backend().bulkRoll(h.Table().values, h.buf, h.position, h.windowSize, stride, h.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.outMask != 0 {
+		for i := range hashes {
+			hashes[i] &= h.outMask
+		}
+	}
+
+	return hashes, nil
+}
+
+// BulkRollMatch implements MatchRoller. The filtering runs inside the bulk
+// loop so only matching windows are materialized.
+func (h *Hasher) BulkRollMatch(mask, target uint64, stride uint32) ([]uint32, []uint64, error) {
+	if stride == 0 {
+		return nil, nil, ErrIllegalStride
+	}
+	if h.pending() {
+		return nil, nil, nil
+	}
+
+	if h.outMask != 0 {
+		// Target bits beyond the output width can never match.
+		if target&mask&^h.outMask != 0 {
+			return nil, nil, nil
+		}
+		mask &= h.outMask
+	}
+
+	positions, hashes, err := h.Backend().bulkRollMatch(h.Table().values, h.buf, h.position, h.windowSize, stride, h.hash, mask, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if h.offset > 0 {
+		for i := range positions {
+			positions[i] += h.offset
+		}
+	}
+	if h.outMask != 0 {
+		for i := range hashes {
+			hashes[i] &= h.outMask
+		}
+	}
 
-	return bulkRoll(h.buf, h.position, h.windowSize, stride, h.hash), nil
+	return positions, hashes, nil
+}
+
+// BulkRollFunc implements FuncRoller.
+func (h *Hasher) BulkRollFunc(stride uint32, fn func(pos uint32, h uint64) bool) error {
+	if stride == 0 {
+		return ErrIllegalStride
+	}
+
+	t := h.Table().values
+	n := uint32(len(h.buf))
+	pos := h.position
+	hash := h.hash
+
+	for pos+h.windowSize <= n {
+		if !fn(h.offset+pos, h.output(hash)) {
+			return nil
+		}
+
+		for i := uint32(0); i < stride; i++ {
+			if pos+h.windowSize >= n {
+				return nil
+			}
+			out := h.buf[pos]
+			in := h.buf[pos+h.windowSize]
+
+			hash = bits.RotateLeft64(hash, 1) ^
+				bits.RotateLeft64(t[out], int(h.windowSize)) ^
+				t[in]
+
+			pos++
+		}
+	}
+
+	return nil
+}
+
+// BulkRollContext implements FuncRoller.
+func (h *Hasher) BulkRollContext(ctx context.Context, stride uint32, fn func(pos uint32, h uint64) bool) error {
+	return bulkRollContext(ctx, stride, fn, h.BulkRollFunc)
+}
+
+// Shared BulkRollContext implementation on top of a BulkRollFunc. The
+// context is checked up front and then every contextCheckInterval bytes
+// rolled over, which is every window once the stride reaches it.
+func bulkRollContext(ctx context.Context, stride uint32, fn func(pos uint32, h uint64) bool,
+	each func(stride uint32, fn func(pos uint32, h uint64) bool) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var ctxErr error
+	interval := max(1, contextCheckInterval/int(max(stride, 1)))
+	visited := 0
+	err := each(stride, func(pos uint32, hash uint64) bool {
+		visited++
+		if visited%interval == 0 {
+			select {
+			case <-ctx.Done():
+				ctxErr = ctx.Err()
+				return false
+			default:
+			}
+		}
+		return fn(pos, hash)
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctxErr
 }
 
 // Creates a new rolling hasher over the given buffer and window size the
@@ -125,12 +359,17 @@ func New(buf []byte, windowSize uint32) (RollingHash, error) {
 
 // Inner method to hash the given bytes in one shot without rolling.
 func hashBuf(p []byte) uint64 {
+	return hashTable(&table, p)
+}
+
+// Inner method to hash the given bytes with the given table values.
+func hashTable(t *[256]uint64, p []byte) uint64 {
 	var h uint64
 	n := len(p)
 
 	for i := 0; i < n; i++ {
 		rot := n - 1 - i
-		h ^= bits.RotateLeft64(table[p[i]], rot)
+		h ^= bits.RotateLeft64(t[p[i]], rot)
 	}
 
 	return h
@@ -143,24 +382,33 @@ func (h *Hasher) Roll(step uint32) (uint64, error) {
 		return 0, ErrIllegalRoll
 	}
 
+	t := h.Table().values
 	for i := uint32(0); i < uint32(step); i++ {
 		out := h.buf[h.position]
 		in := h.buf[h.position+h.windowSize]
 
 		h.hash = bits.RotateLeft64(h.hash, 1) ^
-			bits.RotateLeft64(table[out], int(h.windowSize)) ^
-			table[in]
+			bits.RotateLeft64(t[out], int(h.windowSize)) ^
+			t[in]
 
 		h.position++
 	}
 
-	return h.hash, nil
+	return h.output(h.hash), nil
 }
 
 // Get the hash value of the current state of the hasher. Does not change the
 // state in any way.
 func (h *Hasher) Sum64() uint64 {
-	return h.hash
+	return h.output(h.hash)
+}
+
+// Applies the output width to a hash.
+func (h *Hasher) output(hash uint64) uint64 {
+	if h.outMask == 0 {
+		return hash
+	}
+	return hash & h.outMask
 }
 
 // Sum appends the current hash to b and returns the resulting slice.
@@ -171,10 +419,14 @@ func (h *Hasher) Sum(b []byte) []byte {
 	return append(b, buf[:]...)
 }
 
-// Reset the position of this hasher.
+// Reset the position of this hasher. After Discard has dropped a prefix of
+// the input, the hasher resets to the oldest byte it still holds.
 func (h *Hasher) Reset() {
 	h.position = 0
-	h.hash = hashBuf(h.buf[:h.windowSize])
+	h.hash = 0
+	if !h.pending() {
+		h.hash = hashTable(h.Table().values, h.buf[:h.windowSize])
+	}
 }
 
 // Size returns the number of bytes Sum will return.
@@ -193,7 +445,128 @@ func (h *Hasher) BlockSize() int {
 	return 1
 }
 
+// Clone implements Cloner in O(1). The clone shares the buffer and
+// Append only writes past the input of every hasher sharing it, so the
+// original can be cloned from several goroutines at once.
+func (h *Hasher) Clone() RollingHash {
+	c := *h
+	return &c
+}
+
+// ResetTo reuses the hasher for a new buffer as if it was created by New,
+// without allocating. The backend, table and output width are kept. A zero Hasher
+// can be reset this way, which makes pooling hashers in a sync.Pool practical.
+func (h *Hasher) ResetTo(buf []byte, windowSize uint32) error {
+	if windowSize > uint32(len(buf)) {
+		return ErrWindowTooLong
+	}
+
+	*h = Hasher{
+		buf:        buf,
+		windowSize: windowSize,
+		hash:       hashTable(h.Table().values, buf[:windowSize]),
+		backend:    h.backend,
+		table:      h.table,
+		outMask:    h.outMask,
+	}
+
+	return nil
+}
+
 // Get the current position in the input
 func (h *Hasher) Position() uint32 {
-	return h.position
+	return h.offset + h.position
+}
+
+// Backend returns the backend running the bulk loops of this hasher.
+func (h *Hasher) Backend() *Backend {
+	if h.backend != nil {
+		return h.backend
+	}
+	return DefaultBackend()
+}
+
+// SetBackend pins the backend for the bulk loops of this hasher. A nil
+// backend makes the hasher follow DefaultBackend again.
+func (h *Hasher) SetBackend(b *Backend) {
+	h.backend = b
+}
+
+// Table returns the table the hasher hashes with.
+func (h *Hasher) Table() *Table {
+	if h.table == nil {
+		return tableV1
+	}
+	return h.table
+}
+
+// Creates a new rolling hasher for input that arrives in pieces through
+// Append, e.g. a log tailer. Unlike New, buf may be shorter than the window,
+// including empty; see Hasher for the behaviour until the first window is
+// complete.
+func NewAppendable(buf []byte, windowSize uint32) *Hasher {
+	h := &Hasher{
+		buf:        buf,
+		windowSize: windowSize,
+	}
+	h.Reset()
+
+	return h
+}
+
+// Whether the input does not hold a full window yet, which only a hasher
+// from NewAppendable allows.
+func (h *Hasher) pending() bool {
+	return h.position+h.windowSize > uint32(len(h.buf))
+}
+
+// Tracks how far a buffer allocated by Append has been written. A Hasher and
+// its clones share the buffer, and only the one whose input ends at the
+// written end may append in place; the others copy their input first.
+type appendTail struct {
+	// The input offset just past the last written byte
+	end atomic.Uint64
+}
+
+// Append extends the input with p. Rolling continues from the current
+// position with the same results as a hasher over the concatenated input.
+// No input is dropped, so Reset still returns to position 0; call Discard
+// to release the bytes behind the window.
+//
+// The buffer passed to New is never written to; the first Append copies it
+// into a buffer owned by the hasher. Clones sharing that buffer claim its
+// free capacity atomically, and whoever loses copies its input instead.
+func (h *Hasher) Append(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+
+	wasPending := h.pending()
+	end := uint64(h.offset) + uint64(len(h.buf))
+	if h.tail == nil || cap(h.buf)-len(h.buf) < len(p) || !h.tail.end.CompareAndSwap(end, end+uint64(len(p))) {
+		buf := make([]byte, len(h.buf), 2*(len(h.buf)+len(p)))
+		copy(buf, h.buf)
+		h.buf = buf
+		h.tail = &appendTail{}
+		h.tail.end.Store(end + uint64(len(p)))
+	}
+	h.buf = append(h.buf, p...)
+
+	if wasPending && !h.pending() {
+		h.hash = hashTable(h.Table().values, h.buf[:h.windowSize])
+	}
+}
+
+// Discard drops the input before the current window so that memory stays
+// proportional to the unread input, and returns the number of bytes dropped.
+// Positions keep counting from the start of the original input, so after
+// Discard, Reset rewinds to the oldest byte still held and Position no
+// longer returns to 0.
+func (h *Hasher) Discard() uint32 {
+	n := h.position
+	h.buf = h.buf[n:]
+	h.offset += n
+	h.position = 0
+
+	return n
 }
@@ -2,9 +2,11 @@ package hasher
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"hash"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -193,3 +195,224 @@ func TestReset(t *testing.T) {
 	h.Reset()
 	assert.Equal(t, originalHash, h.Sum64(), "Reset should be idempotent")
 }
+
+func TestBulkRollMatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(21))
+	data := make([]byte, 20000)
+	rng.Read(data)
+	windowSize := uint32(8)
+
+	h, err := New(data, windowSize)
+	assert.NoError(t, err)
+	_, err = h.Roll(5)
+	assert.NoError(t, err)
+	origHash := h.Sum64()
+
+	for _, stride := range []uint32{1, 3} {
+		all, err := h.BulkRoll(stride)
+		assert.NoError(t, err)
+
+		// A loose mask produces enough matches to outgrow any initial chunk.
+		for _, mask := range []uint64{0x0f, 0x3ff, 0} {
+			target := uint64(0x07) & mask
+
+			var wantPos []uint32
+			var wantHashes []uint64
+			for i, hash := range all {
+				if hash&mask == target {
+					wantPos = append(wantPos, 5+uint32(i)*stride)
+					wantHashes = append(wantHashes, hash)
+				}
+			}
+
+			positions, hashes, err := BulkRollMatch(h, mask, target, stride)
+			assert.NoError(t, err)
+			assert.Equal(t, wantPos, positions, "stride %d mask %x", stride, mask)
+			assert.Equal(t, wantHashes, hashes, "stride %d mask %x", stride, mask)
+
+			for i, pos := range positions {
+				assert.Equal(t, Hash(data[pos:pos+windowSize]), hashes[i])
+			}
+		}
+	}
+
+	// Nothing matches an impossible target.
+	positions, hashes, err := BulkRollMatch(h, 0x1, 0x2, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, positions)
+	assert.Empty(t, hashes)
+
+	assert.Equal(t, origHash, h.Sum64(), "BulkRollMatch should not mutate internal state")
+	assert.Equal(t, uint32(5), h.Position())
+
+	_, _, err = BulkRollMatch(h, 0, 0, 0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+}
+
+func TestBulkRollFunc(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	windowSize := uint32(5)
+
+	h, err := New(data, windowSize)
+	assert.NoError(t, err)
+	_, err = h.Roll(2)
+	assert.NoError(t, err)
+	origHash := h.Sum64()
+
+	for _, stride := range []uint32{1, 4} {
+		want, _ := h.BulkRoll(stride)
+
+		var got []uint64
+		err = BulkRollFunc(h, stride, func(pos uint32, hash uint64) bool {
+			assert.Equal(t, Hash(data[pos:pos+windowSize]), hash)
+			assert.Equal(t, uint32(2)+uint32(len(got))*stride, pos)
+			got = append(got, hash)
+			return true
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	// Stop early after three windows.
+	calls := 0
+	err = BulkRollFunc(h, 1, func(pos uint32, hash uint64) bool {
+		calls++
+		return calls < 3
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	assert.Equal(t, origHash, h.Sum64(), "BulkRollFunc should not mutate internal state")
+	assert.Equal(t, uint32(2), h.Position())
+
+	err = BulkRollFunc(h, 0, func(uint32, uint64) bool { return true })
+	assert.ErrorIs(t, err, ErrIllegalStride)
+}
+
+func TestBulkRollContext(t *testing.T) {
+	data := make([]byte, 4*contextCheckInterval)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	h, err := New(data, 6)
+	assert.NoError(t, err)
+	origHash := h.Sum64()
+
+	// Runs to completion with a live context.
+	count := 0
+	err = BulkRollContext(context.Background(), h, 1, func(uint32, uint64) bool {
+		count++
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, len(data)-6+1, count)
+
+	// An already cancelled context never calls fn.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = BulkRollContext(ctx, h, 1, func(uint32, uint64) bool {
+		t.Fatal("fn called with a cancelled context")
+		return true
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Cancelling midway stops at the next check.
+	ctx, cancel = context.WithCancel(context.Background())
+	count = 0
+	err = BulkRollContext(ctx, h, 1, func(uint32, uint64) bool {
+		count++
+		if count == 100 {
+			cancel()
+		}
+		return true
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, count, contextCheckInterval)
+
+	// Large strides check every window rather than every few thousand.
+	ctx, cancel = context.WithCancel(context.Background())
+	count = 0
+	err = BulkRollContext(ctx, h, contextCheckInterval, func(uint32, uint64) bool {
+		count++
+		if count == 2 {
+			cancel()
+		}
+		return true
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, count)
+
+	// Stopping from fn is not an error.
+	err = BulkRollContext(context.Background(), h, 1, func(uint32, uint64) bool { return false })
+	assert.NoError(t, err)
+
+	err = BulkRollContext(context.Background(), h, 0, func(uint32, uint64) bool { return true })
+	assert.ErrorIs(t, err, ErrIllegalStride)
+
+	assert.Equal(t, origHash, h.Sum64(), "BulkRollContext should not mutate internal state")
+	assert.Equal(t, uint32(0), h.Position())
+}
+
+// Exposes only the RollingHash methods of a hasher.
+type plainRollingHash struct {
+	RollingHash
+}
+
+func TestOptionalInterfaces(t *testing.T) {
+	buf := []byte("the quick brown fox jumps over the lazy dog")
+	seed, err := ParseSpacedSeed("1101")
+	assert.NoError(t, err)
+
+	hasher, _ := New(buf, 4)
+	spaced, _ := NewSpaced(buf, seed)
+	segmented, _ := NewSegmented([][]byte{buf[:10], buf[10:]}, 4)
+	symbols, _ := NewSymbols(buf, 4, nil)
+	runes, _ := NewRunes(buf, 4, RuneOptions{})
+	mutable, _ := NewMutable(buf, 4)
+	compat, _ := CompatSilvasur.New(buf, 4)
+
+	for _, h := range []RollingHash{hasher, spaced, segmented, symbols, runes, mutable, compat} {
+		assert.Implements(t, (*MatchRoller)(nil), h)
+		assert.Implements(t, (*FuncRoller)(nil), h)
+		assert.Implements(t, (*Cloner)(nil), h)
+	}
+}
+
+func TestOptionalInterfaceFallbacks(t *testing.T) {
+	buf := make([]byte, 500)
+	rand.New(rand.NewSource(9)).Read(buf)
+
+	h, err := New(buf, 6)
+	assert.NoError(t, err)
+	_, err = h.Roll(5)
+	assert.NoError(t, err)
+	plain := plainRollingHash{h}
+
+	for _, stride := range []uint32{1, 4} {
+		wantPos, wantHashes, err := BulkRollMatch(h, 0x7, 0x5, stride)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, wantPos)
+		positions, hashes, err := BulkRollMatch(plain, 0x7, 0x5, stride)
+		assert.NoError(t, err)
+		assert.Equal(t, wantPos, positions)
+		assert.Equal(t, wantHashes, hashes)
+
+		var want, got []uint32
+		assert.NoError(t, BulkRollFunc(h, stride, func(pos uint32, _ uint64) bool {
+			want = append(want, pos)
+			return len(want) < 20
+		}))
+		assert.NoError(t, BulkRollFunc(plain, stride, func(pos uint32, _ uint64) bool {
+			got = append(got, pos)
+			return len(got) < 20
+		}))
+		assert.Equal(t, want, got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = BulkRollContext(ctx, plain, 1, func(uint32, uint64) bool { return true })
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, _, err = BulkRollMatch(plain, 0, 0, 0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+}
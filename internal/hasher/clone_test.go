@@ -0,0 +1,147 @@
+package hasher
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneSharesBuffer(t *testing.T) {
+	buf := []byte("the quick brown fox jumps over the lazy dog")
+	h, err := New(buf, 8)
+	assert.NoError(t, err)
+	_, err = h.Roll(10)
+	assert.NoError(t, err)
+
+	c := h.(Cloner).Clone().(*Hasher)
+	assert.Equal(t, &buf[0], &c.buf[0])
+	assert.Equal(t, h.Sum64(), c.Sum64())
+
+	// Only the hasher itself is allocated.
+	var sink RollingHash
+	allocs := testing.AllocsPerRun(100, func() {
+		sink = h.(Cloner).Clone()
+	})
+	assert.Equal(t, float64(1), allocs)
+	assert.NotNil(t, sink)
+}
+
+func TestCloneAppendIsIndependent(t *testing.T) {
+	h, err := New([]byte("abcdefgh"), 4)
+	assert.NoError(t, err)
+	hh := h.(*Hasher)
+
+	// Own the buffer first so that Append could write into it in place.
+	hh.Append([]byte("ijkl"))
+	c := hh.Clone().(*Hasher)
+
+	hh.Append([]byte("XXXX"))
+	c.Append([]byte("YYYY"))
+
+	_, err = hh.Roll(12)
+	assert.NoError(t, err)
+	_, err = c.Roll(12)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash([]byte("XXXX")), hh.Sum64())
+	assert.Equal(t, Hash([]byte("YYYY")), c.Sum64())
+}
+
+func TestCloneConcurrently(t *testing.T) {
+	h := NewAppendable([]byte("abcdefgh"), 4)
+	// Leave free capacity behind the input that clones could write into.
+	h.Append([]byte("ijkl"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(suffix byte) {
+			defer wg.Done()
+
+			c := h.Clone().(*Hasher)
+			c.Append([]byte{suffix, suffix, suffix, suffix})
+			_, err := c.Roll(12)
+			assert.NoError(t, err)
+			assert.Equal(t, Hash([]byte{suffix, suffix, suffix, suffix}), c.Sum64())
+		}(byte('0' + i))
+	}
+	wg.Wait()
+
+	hashes, err := h.BulkRoll(1)
+	assert.NoError(t, err)
+	assert.Len(t, hashes, 9)
+	assert.Equal(t, Hash([]byte("ijkl")), hashes[8])
+}
+
+func TestCloneOtherHashers(t *testing.T) {
+	seed, err := ParseSpacedSeed("1101")
+	assert.NoError(t, err)
+	spaced, err := NewSpaced([]byte("abcdefghij"), seed)
+	assert.NoError(t, err)
+	mutable, err := NewMutable([]byte("abcdefghij"), 3)
+	assert.NoError(t, err)
+	mutable.CacheHashes()
+	runes, err := NewRunes([]byte("ünïcödé text"), 3, RuneOptions{})
+	assert.NoError(t, err)
+
+	for _, h := range []Cloner{spaced.(Cloner), mutable, runes} {
+		c := h.Clone()
+		_, err := c.Roll(2)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(0), h.Position())
+
+		_, err = h.Roll(2)
+		assert.NoError(t, err)
+		assert.Equal(t, c.Sum64(), h.Sum64())
+	}
+
+	// The clone of a rune hasher keeps its byte offsets.
+	start, end := runes.Clone().(*RuneHasher).Offsets()
+	assert.Equal(t, "ïcö", string(runes.Bytes()[start:end]))
+
+	// Edits to a mutable clone leave the original alone.
+	c := mutable.Clone().(*MutableHasher)
+	assert.NoError(t, c.SetByte(3, 'X'))
+	assert.Equal(t, "abcdefghij", string(mutable.Bytes()))
+	assert.Equal(t, Hash([]byte("cde")), mutable.CacheHashes()[2])
+}
+
+func TestResetTo(t *testing.T) {
+	h := &Hasher{}
+	assert.NoError(t, h.ResetTo([]byte("hello world"), 5))
+	assert.Equal(t, Hash([]byte("hello")), h.Sum64())
+	hash, err := h.Roll(6)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash([]byte("world")), hash)
+
+	// The backend survives, everything else starts over.
+	h.Append([]byte("!"))
+	h.SetBackend(goBackend)
+	assert.NoError(t, h.ResetTo([]byte("abc"), 2))
+	assert.Equal(t, goBackend, h.Backend())
+	assert.Equal(t, uint32(0), h.Position())
+	assert.Nil(t, h.tail)
+	assert.Equal(t, Hash([]byte("ab")), h.Sum64())
+
+	assert.ErrorIs(t, h.ResetTo([]byte("abc"), 4), ErrWindowTooLong)
+
+	buf := []byte("pooled hashers do not allocate")
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = h.ResetTo(buf, 8)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestResetToPool(t *testing.T) {
+	pool := sync.Pool{New: func() any { return new(Hasher) }}
+	inputs := []string{"first input", "second input", "third one"}
+
+	for _, in := range inputs {
+		h := pool.Get().(*Hasher)
+		assert.NoError(t, h.ResetTo([]byte(in), 5))
+		hashes, err := h.BulkRoll(1)
+		assert.NoError(t, err)
+		assert.Equal(t, Hash([]byte(in[len(in)-5:])), hashes[len(hashes)-1])
+		pool.Put(h)
+	}
+}
@@ -0,0 +1,22 @@
+package hasher
+
+import "math/bits"
+
+// Combine returns the hash of A||B given the hashes of A and B and the
+// length of B. Every byte of A is rotated lenB positions further than it is
+// in H(A), so H(A||B) = rotl(H(A), lenB) ^ H(B).
+func Combine(hA, hB uint64, lenB int) uint64 {
+	return bits.RotateLeft64(hA, lenB) ^ hB
+}
+
+// StripPrefix returns the hash of B given the hash of A||B, the hash of the
+// prefix A and the length of B. It inverts Combine.
+func StripPrefix(hAB, hA uint64, lenB int) uint64 {
+	return hAB ^ bits.RotateLeft64(hA, lenB)
+}
+
+// StripSuffix returns the hash of A given the hash of A||B, the hash of the
+// suffix B and the length of B. It inverts Combine.
+func StripSuffix(hAB, hB uint64, lenB int) uint64 {
+	return bits.RotateLeft64(hAB^hB, -lenB)
+}
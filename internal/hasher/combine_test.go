@@ -0,0 +1,58 @@
+package hasher
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineRandomSplits(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+
+	for trial := 0; trial < 500; trial++ {
+		data := make([]byte, rng.Intn(200))
+		rng.Read(data)
+		split := 0
+		if len(data) > 0 {
+			split = rng.Intn(len(data) + 1)
+		}
+
+		a, b := data[:split], data[split:]
+		hA, hB, hAB := Hash(a), Hash(b), Hash(data)
+
+		assert.Equal(t, hAB, Combine(hA, hB, len(b)), "split %d of %d", split, len(data))
+		assert.Equal(t, hB, StripPrefix(hAB, hA, len(b)), "split %d of %d", split, len(data))
+		assert.Equal(t, hA, StripSuffix(hAB, hB, len(b)), "split %d of %d", split, len(data))
+	}
+}
+
+func TestCombineParallelChunks(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	data := make([]byte, 10000)
+	rng.Read(data)
+
+	const chunk = 1024
+	var chunks [][]byte
+	for i := 0; i < len(data); i += chunk {
+		chunks = append(chunks, data[i:min(i+chunk, len(data))])
+	}
+
+	hashes := make([]uint64, len(chunks))
+	var wg sync.WaitGroup
+	for i := range chunks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hashes[i] = Hash(chunks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var combined uint64
+	for i, h := range hashes {
+		combined = Combine(combined, h, len(chunks[i]))
+	}
+	assert.Equal(t, Hash(data), combined)
+}
@@ -0,0 +1,101 @@
+package hasher
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The vectors in testdata/compat.json were generated by running the reference
+// implementations, rolling byte by byte over every input.
+type compatVector struct {
+	Mode   string   `json:"mode"`
+	Window uint32   `json:"window"`
+	Input  string   `json:"input"`
+	Hashes []uint64 `json:"hashes"`
+}
+
+var compatModes = map[string]*Compat{
+	"rollinghash64": CompatRollinghash64,
+	"rollinghash32": CompatRollinghash32,
+	"silvasur":      CompatSilvasur,
+}
+
+func TestCompatGoldenVectors(t *testing.T) {
+	data, err := os.ReadFile("testdata/compat.json")
+	assert.NoError(t, err)
+
+	var vectors []compatVector
+	assert.NoError(t, json.Unmarshal(data, &vectors))
+	assert.NotEmpty(t, vectors)
+
+	seen := map[string]bool{}
+	for _, v := range vectors {
+		c := compatModes[v.Mode]
+		if !assert.NotNil(t, c, "unknown mode %s", v.Mode) {
+			continue
+		}
+		seen[v.Mode] = true
+
+		input, err := hex.DecodeString(v.Input)
+		assert.NoError(t, err)
+
+		h, err := c.New(input, v.Window)
+		assert.NoError(t, err)
+		hashes, err := h.BulkRoll(1)
+		assert.NoError(t, err)
+		assert.Equal(t, v.Hashes, hashes, "%s window %d", v.Mode, v.Window)
+
+		for i, want := range v.Hashes {
+			assert.Equal(t, want, c.Hash(input[i:i+int(v.Window)]))
+		}
+	}
+	assert.Len(t, seen, len(compatModes))
+}
+
+func TestCompatRollinghash64MatchesTableScheme(t *testing.T) {
+	// rollinghash uses the same 64-bit convention, so only the table differs.
+	values := CompatRollinghash64.values
+	custom := &Table{values: &values}
+	buf := []byte("The quick brown fox jumps over the lazy dog")
+
+	h, err := custom.New(buf, 9)
+	assert.NoError(t, err)
+	c, err := CompatRollinghash64.New(buf, 9)
+	assert.NoError(t, err)
+
+	want, err := h.BulkRoll(2)
+	assert.NoError(t, err)
+	got, err := c.BulkRoll(2)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestCompatHasher(t *testing.T) {
+	buf := []byte("hello compat world")
+	h, err := CompatSilvasur.New(buf, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, CompatSilvasur, h.(*CompatHasher).Compat())
+
+	hash, err := h.Roll(6)
+	assert.NoError(t, err)
+	assert.Equal(t, CompatSilvasur.Hash([]byte("compa")), hash)
+
+	c := h.(Cloner).Clone()
+	h.Reset()
+	assert.Equal(t, uint32(6), c.Position())
+	assert.Equal(t, CompatSilvasur.Hash([]byte("hello")), h.Sum64())
+
+	positions, hashes, err := BulkRollMatch(h, 0, 0, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{0, 4, 8, 12}, positions)
+	assert.Len(t, hashes, 4)
+
+	_, err = CompatSilvasur.New(buf, 100)
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+	_, err = h.Roll(100)
+	assert.ErrorIs(t, err, ErrIllegalRoll)
+}
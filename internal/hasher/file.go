@@ -0,0 +1,100 @@
+package hasher
+
+import (
+	"errors"
+	"math"
+	"os"
+	"sync/atomic"
+)
+
+var ErrFileTooLarge = errors.New("the file is larger than the 4 GiB positions or the address space can cover")
+
+// File is a Hasher over the contents of a file, memory-mapped read-only
+// where supported and read into memory elsewhere. Close empties the hasher
+// so it cannot roll any more. Clones are Files sharing the mapping, which is
+// released once the File and all its clones are closed.
+type File struct {
+	*Hasher
+	// The contents shared with clones, nil once closed or for empty files
+	contents *fileContents
+}
+
+// The mapped or read contents of a file, shared by a File and its clones.
+type fileContents struct {
+	data []byte
+	// Releases data, nil when there is nothing to release
+	unmap func([]byte) error
+	// The number of Files not closed yet
+	refs atomic.Int32
+}
+
+// OpenFile opens the file at path and creates a rolling hasher over its
+// contents with the window starting from 0 index. Empty files are not mapped
+// and produce an empty hasher.
+func OpenFile(path string, windowSize uint32) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	// Positions are uint32, and on 32-bit platforms the mapping's length
+	// has to fit an int as well.
+	if info.Size() > math.MaxUint32 || info.Size() > math.MaxInt {
+		return nil, ErrFileTooLarge
+	}
+
+	file := &File{}
+	var data []byte
+	if info.Size() > 0 {
+		var unmap func([]byte) error
+		data, unmap, err = mapFile(f, int(info.Size()))
+		if err != nil {
+			return nil, err
+		}
+		file.contents = &fileContents{data: data, unmap: unmap}
+		file.contents.refs.Store(1)
+	}
+
+	h, err := New(data, windowSize)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	file.Hasher = h.(*Hasher)
+
+	return file, nil
+}
+
+// Clone implements Cloner. The clone is a *File sharing the contents and
+// has to be closed as well.
+func (f *File) Clone() RollingHash {
+	c := &File{
+		Hasher:   f.Hasher.Clone().(*Hasher),
+		contents: f.contents,
+	}
+	if c.contents != nil {
+		c.contents.refs.Add(1)
+	}
+
+	return c
+}
+
+// Close empties the hasher and releases the file contents once the File and
+// all its clones are closed. It is safe to call more than once.
+func (f *File) Close() error {
+	if f.Hasher != nil {
+		*f.Hasher = Hasher{backend: f.Hasher.backend}
+	}
+
+	contents := f.contents
+	f.contents = nil
+	if contents == nil || contents.refs.Add(-1) > 0 || contents.unmap == nil {
+		return nil
+	}
+	return contents.unmap(contents.data)
+}
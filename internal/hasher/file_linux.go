@@ -0,0 +1,18 @@
+//go:build linux
+
+package hasher
+
+import (
+	"os"
+	"syscall"
+)
+
+// Maps the first size bytes of the file read-only. The mapping outlives the
+// file descriptor.
+func mapFile(f *os.File, size int) ([]byte, func([]byte) error, error) {
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, &os.PathError{Op: "mmap", Path: f.Name(), Err: err}
+	}
+	return data, syscall.Munmap, nil
+}
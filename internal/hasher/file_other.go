@@ -0,0 +1,17 @@
+//go:build !linux
+
+package hasher
+
+import (
+	"io"
+	"os"
+)
+
+// Reads the file into memory where mapping is not supported.
+func mapFile(f *os.File, size int) ([]byte, func([]byte) error, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, nil, err
+	}
+	return data, nil, nil
+}
@@ -0,0 +1,128 @@
+package hasher
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTempFile(t *testing.T, data []byte) string {
+	path := filepath.Join(t.TempDir(), "input")
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestOpenFileMatchesNew(t *testing.T) {
+	data := make([]byte, 100000)
+	rand.New(rand.NewSource(1)).Read(data)
+	path := writeTempFile(t, data)
+
+	f, err := OpenFile(path, 64)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	h, err := New(data, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, h.Sum64(), f.Sum64())
+
+	want, err := h.BulkRoll(7)
+	assert.NoError(t, err)
+	got, err := f.BulkRoll(7)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	hash, err := f.Roll(1000)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(data[1000:1064]), hash)
+	assert.Equal(t, uint32(1000), f.Position())
+}
+
+func TestOpenFileEmpty(t *testing.T) {
+	path := writeTempFile(t, nil)
+
+	f, err := OpenFile(path, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(nil), f.Sum64())
+
+	hashes, err := f.BulkRoll(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{Hash(nil)}, hashes)
+
+	positions, _, err := f.BulkRollMatch(0, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{0}, positions)
+
+	_, err = f.Roll(1)
+	assert.ErrorIs(t, err, ErrIllegalRoll)
+	assert.NoError(t, f.Close())
+
+	_, err = OpenFile(path, 1)
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+}
+
+func TestOpenFileClose(t *testing.T) {
+	path := writeTempFile(t, []byte("hello world"))
+
+	f, err := OpenFile(path, 4)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	assert.NoError(t, f.Close())
+
+	// The hasher is left empty instead of addressing the released contents.
+	_, err = f.Roll(1)
+	assert.ErrorIs(t, err, ErrIllegalRoll)
+	hashes, err := f.BulkRoll(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{Hash(nil)}, hashes)
+}
+
+func TestOpenFileErrors(t *testing.T) {
+	_, err := OpenFile(filepath.Join(t.TempDir(), "missing"), 1)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = OpenFile(writeTempFile(t, []byte("abc")), 4)
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+}
+
+func TestOpenFileCloneOutlivesClose(t *testing.T) {
+	data := make([]byte, 10000)
+	rand.New(rand.NewSource(2)).Read(data)
+
+	f, err := OpenFile(writeTempFile(t, data), 32)
+	assert.NoError(t, err)
+	_, err = f.Roll(100)
+	assert.NoError(t, err)
+
+	c, ok := f.Clone().(*File)
+	assert.True(t, ok)
+	other := c.Clone().(*File)
+	contents := f.contents
+	assert.Equal(t, int32(3), contents.refs.Load())
+
+	// Closing the original keeps the contents alive for the clones.
+	assert.NoError(t, f.Close())
+	assert.NoError(t, f.Close())
+	hash, err := c.Roll(5000)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(data[5100:5132]), hash)
+
+	assert.NoError(t, c.Close())
+	assert.Equal(t, int32(1), contents.refs.Load())
+	hashes, err := other.BulkRoll(1000)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(data[9100:9132]), hashes[9])
+
+	// The last close releases them and leaves the clone empty.
+	assert.NoError(t, other.Close())
+	assert.Equal(t, int32(0), contents.refs.Load())
+	_, err = other.Roll(1)
+	assert.ErrorIs(t, err, ErrIllegalRoll)
+	assert.NoError(t, other.Close())
+
+	// Clones of a closed File are empty as well.
+	_, err = f.Clone().Roll(1)
+	assert.ErrorIs(t, err, ErrIllegalRoll)
+}
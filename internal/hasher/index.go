@@ -0,0 +1,43 @@
+package hasher
+
+import (
+	"errors"
+	"math/bits"
+)
+
+var ErrIllegalRange = errors.New("the range is outside of the indexed buffer")
+
+// HashIndex answers the hash of any substring of a buffer in O(1).
+//
+// Since buzhash is an XOR of rotated table entries, storing every byte's
+// entry rotated right by its offset makes prefix values composable: the hash
+// of buf[i:j] is the XOR of prefixes j and i rotated left by j-1.
+type HashIndex struct {
+	// prefix[i] is the XOR of table[buf[t]] rotated right by t for all t < i
+	prefix []uint64
+}
+
+// Builds the prefix index over the buffer in O(n). The buffer is not
+// retained.
+func NewHashIndex(buf []byte) *HashIndex {
+	prefix := make([]uint64, len(buf)+1)
+	for t, b := range buf {
+		prefix[t+1] = prefix[t] ^ bits.RotateLeft64(table[b], -t)
+	}
+
+	return &HashIndex{prefix: prefix}
+}
+
+// HashRange returns the hash of buf[i:j], identical to Hash(buf[i:j]).
+func (x *HashIndex) HashRange(i, j uint32) (uint64, error) {
+	if i > j || uint64(j) >= uint64(len(x.prefix)) {
+		return 0, ErrIllegalRange
+	}
+
+	return bits.RotateLeft64(x.prefix[j]^x.prefix[i], int(j)-1), nil
+}
+
+// Len returns the length of the indexed buffer.
+func (x *HashIndex) Len() uint32 {
+	return uint32(len(x.prefix) - 1)
+}
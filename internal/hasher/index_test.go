@@ -0,0 +1,57 @@
+package hasher
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashRangeMatchesHash(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	data := make([]byte, 300)
+	rng.Read(data)
+
+	idx := NewHashIndex(data)
+	assert.Equal(t, uint32(len(data)), idx.Len())
+
+	for i := 0; i <= len(data); i += 7 {
+		for j := i; j <= len(data); j += 5 {
+			got, err := idx.HashRange(uint32(i), uint32(j))
+			assert.NoError(t, err)
+			assert.Equal(t, Hash(data[i:j]), got, "range [%d, %d)", i, j)
+		}
+	}
+}
+
+func TestHashRangeMatchesRolling(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	idx := NewHashIndex(data)
+
+	h, err := New(data, 6)
+	assert.NoError(t, err)
+	hashes, err := h.BulkRoll(1)
+	assert.NoError(t, err)
+
+	for i, want := range hashes {
+		got, err := idx.HashRange(uint32(i), uint32(i+6))
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestHashRangeErrors(t *testing.T) {
+	idx := NewHashIndex([]byte("abc"))
+
+	_, err := idx.HashRange(2, 1)
+	assert.ErrorIs(t, err, ErrIllegalRange)
+	_, err = idx.HashRange(0, 4)
+	assert.ErrorIs(t, err, ErrIllegalRange)
+
+	empty, err := idx.HashRange(3, 3)
+	assert.NoError(t, err)
+	assert.Zero(t, empty)
+
+	_, err = NewHashIndex(nil).HashRange(0, 0)
+	assert.NoError(t, err)
+}
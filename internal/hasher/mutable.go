@@ -0,0 +1,353 @@
+package hasher
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"slices"
+)
+
+var ErrIllegalEdit = errors.New("the edit is outside of the buffer or leaves it shorter than the window")
+
+// Implements RollingHash over a buffer that can be edited in place, e.g. the
+// text of an editor. Edits patch the current window hash, and the hash of
+// every window once CacheHashes is enabled, instead of rehashing the buffer.
+// Changing one byte costs a rotation and an XOR per window containing it,
+// inserting or deleting k bytes rehashes only the k+windowSize-1 windows
+// overlapping the edit.
+//
+// The current window follows its content: it moves along when bytes are
+// inserted or deleted before it, and stays in place when an edit overlaps it.
+type MutableHasher struct {
+	// The buffer to hash over, owned by the hasher
+	buf []byte
+	// The window size for calculating the hash
+	windowSize uint32
+	// The current window start position
+	position uint32
+	// The current pre-computed hash
+	hash uint64
+	// The hash of every window by start position, nil when not cached
+	cache []uint64
+}
+
+// Creates a new mutable rolling hasher over a copy of buf with the window
+// starting from 0 index.
+func NewMutable(buf []byte, windowSize uint32) (*MutableHasher, error) {
+	if windowSize > uint32(len(buf)) {
+		return nil, ErrWindowTooLong
+	}
+
+	h := &MutableHasher{
+		buf:        slices.Clone(buf),
+		windowSize: windowSize,
+	}
+	h.Reset()
+
+	return h, nil
+}
+
+// Bytes returns the current buffer. It is only valid until the next edit and
+// must not be modified.
+func (h *MutableHasher) Bytes() []byte {
+	return h.buf
+}
+
+// The number of windows in the buffer.
+func (h *MutableHasher) windows() int {
+	return len(h.buf) - int(h.windowSize) + 1
+}
+
+// CacheHashes computes the hash of every window and keeps them up to date
+// across edits from now on. The returned slice is indexed by window start
+// position and owned by the hasher, so copy it to keep a snapshot.
+func (h *MutableHasher) CacheHashes() []uint64 {
+	if h.cache == nil {
+		h.cache = make([]uint64, h.windows())
+		h.fill(0, len(h.cache))
+	}
+	return h.cache
+}
+
+// Recomputes the cached hashes of the windows starting in [lo, hi).
+func (h *MutableHasher) fill(lo, hi int) {
+	if lo >= hi {
+		return
+	}
+
+	w := int(h.windowSize)
+	hash := hashBuf(h.buf[lo : lo+w])
+	h.cache[lo] = hash
+	for pos := lo + 1; pos < hi; pos++ {
+		hash = bits.RotateLeft64(hash, 1) ^
+			bits.RotateLeft64(table[h.buf[pos-1]], w) ^
+			table[h.buf[pos+w-1]]
+		h.cache[pos] = hash
+	}
+}
+
+// SetByte replaces the byte at i and patches the hashes of the windows
+// containing it.
+func (h *MutableHasher) SetByte(i uint32, b byte) error {
+	if i >= uint32(len(h.buf)) {
+		return ErrIllegalEdit
+	}
+
+	delta := table[h.buf[i]] ^ table[b]
+	h.buf[i] = b
+	if delta == 0 {
+		return nil
+	}
+
+	// The byte at i is rotated by the number of bytes after it in a window.
+	if h.position <= i && i < h.position+h.windowSize {
+		h.hash ^= bits.RotateLeft64(delta, int(h.position+h.windowSize-1-i))
+	}
+
+	if h.cache != nil && h.windowSize > 0 {
+		lo := i - min(i, h.windowSize-1)
+		hi := min(i, uint32(len(h.cache)-1))
+		for pos := lo; pos <= hi; pos++ {
+			h.cache[pos] ^= bits.RotateLeft64(delta, int(pos+h.windowSize-1-i))
+		}
+	}
+
+	return nil
+}
+
+// The first window that may contain the byte at i, or i for empty windows.
+func (h *MutableHasher) firstWindowAt(i uint32) uint32 {
+	return i - min(i, max(h.windowSize, 1)-1)
+}
+
+// Insert inserts p before the byte at i, or appends it when i is the length
+// of the buffer.
+func (h *MutableHasher) Insert(i uint32, p []byte) error {
+	if i > uint32(len(h.buf)) {
+		return ErrIllegalEdit
+	}
+	if len(p) == 0 {
+		return nil
+	}
+
+	k := uint32(len(p))
+	h.buf = slices.Insert(h.buf, int(i), p...)
+
+	if h.cache != nil {
+		// Windows ending before i and starting from i keep their hashes.
+		lo := h.firstWindowAt(i)
+		tail := min(int(i), len(h.cache))
+		h.spliceCache(int(lo), tail)
+	}
+
+	switch {
+	case h.position >= i:
+		h.position += k
+	case h.position+h.windowSize > i:
+		h.rehash()
+	}
+
+	return nil
+}
+
+// Delete removes the n bytes starting at i. The buffer must stay at least as
+// long as the window.
+func (h *MutableHasher) Delete(i, n uint32) error {
+	if uint64(i)+uint64(n) > uint64(len(h.buf)) || uint32(len(h.buf))-n < h.windowSize {
+		return ErrIllegalEdit
+	}
+	if n == 0 {
+		return nil
+	}
+
+	h.buf = slices.Delete(h.buf, int(i), int(i+n))
+
+	if h.cache != nil {
+		// Windows ending before i and starting from i+n keep their hashes.
+		lo := h.firstWindowAt(i)
+		tail := min(int(i+n), len(h.cache))
+		h.spliceCache(int(lo), tail)
+	}
+
+	switch {
+	case h.position >= i+n:
+		h.position -= n
+	case h.position+h.windowSize > i:
+		h.position = min(h.position, i, uint32(h.windows()-1))
+		h.rehash()
+	}
+
+	return nil
+}
+
+// Replaces the cached hashes of the old windows in [lo, tail) with the
+// recomputed hashes of the windows now between them.
+func (h *MutableHasher) spliceCache(lo, tail int) {
+	kept := len(h.cache) - tail
+	mid := h.windows() - lo - kept
+	h.cache = slices.Replace(h.cache, lo, tail, make([]uint64, mid)...)
+	h.fill(lo, lo+mid)
+}
+
+// Recomputes the hash of the current window.
+func (h *MutableHasher) rehash() {
+	if h.cache != nil {
+		h.hash = h.cache[h.position]
+		return
+	}
+	h.hash = hashBuf(h.buf[h.position : h.position+h.windowSize])
+}
+
+// Rolls the hasing window by the given step. Changes the window start position.
+func (h *MutableHasher) Roll(step uint32) (uint64, error) {
+	if h.position+step+h.windowSize > uint32(len(h.buf)) {
+		return 0, ErrIllegalRoll
+	}
+
+	if h.cache != nil {
+		h.position += step
+		h.hash = h.cache[h.position]
+		return h.hash, nil
+	}
+
+	for i := uint32(0); i < step; i++ {
+		h.hash = bits.RotateLeft64(h.hash, 1) ^
+			bits.RotateLeft64(table[h.buf[h.position]], int(h.windowSize)) ^
+			table[h.buf[h.position+h.windowSize]]
+		h.position++
+	}
+
+	return h.hash, nil
+}
+
+// BulkRoll implements RollingHash.
+func (h *MutableHasher) BulkRoll(stride uint32) ([]uint64, error) {
+	if stride == 0 {
+		return nil, ErrIllegalStride
+	}
+
+	hashes := make([]uint64, 0, (uint32(len(h.buf))-h.windowSize-h.position)/stride+1)
+	err := h.BulkRollFunc(stride, func(_ uint32, hash uint64) bool {
+		hashes = append(hashes, hash)
+		return true
+	})
+
+	return hashes, err
+}
+
+// BulkRollMatch implements MatchRoller.
+func (h *MutableHasher) BulkRollMatch(mask, target uint64, stride uint32) ([]uint32, []uint64, error) {
+	var positions []uint32
+	var hashes []uint64
+	err := h.BulkRollFunc(stride, func(pos uint32, hash uint64) bool {
+		if hash&mask == target {
+			positions = append(positions, pos)
+			hashes = append(hashes, hash)
+		}
+		return true
+	})
+
+	return positions, hashes, err
+}
+
+// BulkRollFunc implements FuncRoller. Cached hashes are read instead of
+// rolled.
+func (h *MutableHasher) BulkRollFunc(stride uint32, fn func(pos uint32, h uint64) bool) error {
+	if stride == 0 {
+		return ErrIllegalStride
+	}
+
+	if h.cache != nil {
+		for pos := int(h.position); pos < len(h.cache); pos += int(stride) {
+			if !fn(uint32(pos), h.cache[pos]) {
+				return nil
+			}
+		}
+		return nil
+	}
+
+	n := uint32(len(h.buf))
+	pos := h.position
+	hash := h.hash
+
+	for pos+h.windowSize <= n {
+		if !fn(pos, hash) {
+			return nil
+		}
+
+		for i := uint32(0); i < stride; i++ {
+			if pos+h.windowSize >= n {
+				return nil
+			}
+
+			hash = bits.RotateLeft64(hash, 1) ^
+				bits.RotateLeft64(table[h.buf[pos]], int(h.windowSize)) ^
+				table[h.buf[pos+h.windowSize]]
+			pos++
+		}
+	}
+
+	return nil
+}
+
+// BulkRollContext implements FuncRoller.
+func (h *MutableHasher) BulkRollContext(ctx context.Context, stride uint32, fn func(pos uint32, h uint64) bool) error {
+	return bulkRollContext(ctx, stride, fn, h.BulkRollFunc)
+}
+
+// Get the hash value of the current state of the hasher. Does not change the
+// state in any way.
+func (h *MutableHasher) Sum64() uint64 {
+	return h.hash
+}
+
+// Sum appends the current hash to b and returns the resulting slice.
+// It does not change the underlying hash state.
+func (h *MutableHasher) Sum(b []byte) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], h.Sum64())
+	return append(b, buf[:]...)
+}
+
+// Reset the position of this hasher.
+func (h *MutableHasher) Reset() {
+	h.position = 0
+	h.rehash()
+}
+
+// Size returns the number of bytes Sum will return.
+func (h *MutableHasher) Size() int {
+	return hashSizeBytes
+}
+
+// Not implemented, edit the buffer with SetByte, Insert and Delete instead.
+func (h *MutableHasher) Write(p []byte) (int, error) {
+	return 0, ErrNotWritable
+}
+
+// In buzhash context, a block size doesn't have any impact
+func (h *MutableHasher) BlockSize() int {
+	return 1
+}
+
+// Get the current position in the input
+func (h *MutableHasher) Position() uint32 {
+	return h.position
+}
+
+// Clone implements Cloner. The buffer and the cached hashes are copied
+// since both hashers may edit them, which costs O(n).
+func (h *MutableHasher) Clone() RollingHash {
+	c := *h
+	c.buf = slices.Clone(h.buf)
+	if h.cache != nil {
+		c.cache = slices.Clone(h.cache)
+	}
+	return &c
+}
+
+// Table returns the table the hasher hashes with, always TableV1.
+func (h *MutableHasher) Table() *Table {
+	return tableV1
+}
@@ -0,0 +1,135 @@
+package hasher
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Checks the current window and every cached window against Hash.
+func assertMutableConsistent(t *testing.T, h *MutableHasher) {
+	t.Helper()
+
+	buf := h.Bytes()
+	w := h.windowSize
+	assert.Equal(t, Hash(buf[h.Position():h.Position()+w]), h.Sum64())
+
+	if h.cache != nil {
+		assert.Len(t, h.cache, len(buf)-int(w)+1)
+		for pos := range h.cache {
+			if !assert.Equal(t, Hash(buf[pos:pos+int(w)]), h.cache[pos], "window %d", pos) {
+				return
+			}
+		}
+	}
+}
+
+func TestMutableSetByte(t *testing.T) {
+	h, err := NewMutable([]byte("hello world, hello buzhash"), 5)
+	assert.NoError(t, err)
+	h.CacheHashes()
+
+	_, err = h.Roll(4)
+	assert.NoError(t, err)
+
+	for _, i := range []uint32{0, 3, 4, 6, 8, 9, 25} {
+		assert.NoError(t, h.SetByte(i, 'X'))
+		assertMutableConsistent(t, h)
+	}
+	assert.Equal(t, "XelXX XoXXd, hello buzhasX", string(h.Bytes()))
+
+	assert.ErrorIs(t, h.SetByte(26, 'X'), ErrIllegalEdit)
+}
+
+func TestMutableInsertDelete(t *testing.T) {
+	h, err := NewMutable([]byte("0123456789"), 3)
+	assert.NoError(t, err)
+
+	_, err = h.Roll(5)
+	assert.NoError(t, err)
+
+	// Inserting before the window moves it along with its content.
+	assert.NoError(t, h.Insert(2, []byte("ab")))
+	assert.Equal(t, "01ab23456789", string(h.Bytes()))
+	assert.Equal(t, uint32(7), h.Position())
+	assertMutableConsistent(t, h)
+
+	// Inserting inside the window keeps its position.
+	assert.NoError(t, h.Insert(8, []byte("c")))
+	assert.Equal(t, uint32(7), h.Position())
+	assertMutableConsistent(t, h)
+
+	// Deleting the start of the window moves it to the deletion point.
+	assert.NoError(t, h.Delete(6, 3))
+	assert.Equal(t, "01ab236789", string(h.Bytes()))
+	assert.Equal(t, uint32(6), h.Position())
+	assertMutableConsistent(t, h)
+
+	assert.NoError(t, h.Insert(uint32(len(h.Bytes())), []byte("end")))
+	assert.NoError(t, h.Delete(0, 2))
+	assert.Equal(t, uint32(4), h.Position())
+	assertMutableConsistent(t, h)
+
+	assert.ErrorIs(t, h.Insert(100, []byte("x")), ErrIllegalEdit)
+	assert.ErrorIs(t, h.Delete(5, 100), ErrIllegalEdit)
+	assert.ErrorIs(t, h.Delete(0, uint32(len(h.Bytes()))-2), ErrIllegalEdit)
+}
+
+func TestMutableRandomEdits(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+
+	for _, windowSize := range []uint32{0, 1, 2, 7, 32} {
+		for _, cached := range []bool{false, true} {
+			buf := make([]byte, 64)
+			rng.Read(buf)
+			h, err := NewMutable(buf, windowSize)
+			assert.NoError(t, err)
+			if cached {
+				h.CacheHashes()
+			}
+
+			for step := 0; step < 300; step++ {
+				n := uint32(len(h.Bytes()))
+				switch rng.Intn(4) {
+				case 0:
+					if n > 0 {
+						assert.NoError(t, h.SetByte(uint32(rng.Intn(int(n))), byte(rng.Intn(256))))
+					}
+				case 1:
+					p := make([]byte, rng.Intn(10))
+					rng.Read(p)
+					assert.NoError(t, h.Insert(uint32(rng.Intn(int(n)+1)), p))
+				case 2:
+					if n > windowSize {
+						k := uint32(rng.Intn(int(min(n-windowSize, 10)))) + 1
+						assert.NoError(t, h.Delete(uint32(rng.Intn(int(n-k+1))), k))
+					}
+				case 3:
+					if _, err := h.Roll(1); err != nil {
+						h.Reset()
+					}
+				}
+				assertMutableConsistent(t, h)
+			}
+
+			hashes, err := h.BulkRoll(1)
+			assert.NoError(t, err)
+			for i, hash := range hashes {
+				pos := int(h.Position()) + i
+				assert.Equal(t, Hash(h.Bytes()[pos:pos+int(windowSize)]), hash)
+			}
+		}
+	}
+}
+
+func TestMutableCopiesInput(t *testing.T) {
+	buf := []byte("abcdef")
+	h, err := NewMutable(buf, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, h.SetByte(0, 'z'))
+	assert.Equal(t, "abcdef", string(buf))
+
+	_, err = NewMutable(buf, 7)
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+}
@@ -0,0 +1,143 @@
+package hasher
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrIllegalOption = errors.New("illegal option")
+
+// An Option configures a hasher created by NewWithOptions.
+type Option func(*config) error
+
+// The configuration collected from the options passed to NewWithOptions.
+type config struct {
+	// The table to hash with, nil for TableV1
+	table *Table
+	// The seed to derive the table with, if any
+	seeded bool
+	seed   uint64
+	// The implementation to reproduce instead of a table, if any
+	compat *Compat
+	// The backend for bulk loops, nil to follow DefaultBackend
+	backend *Backend
+	// The number of low bits kept in every hash, 0 for all
+	outputBits uint
+}
+
+// Fails with ErrIllegalOption naming the option.
+func optionError(option, format string, args ...any) error {
+	return fmt.Errorf("%w: %s: %s", ErrIllegalOption, option, fmt.Sprintf(format, args...))
+}
+
+// WithTable hashes with the given table instead of TableV1.
+func WithTable(t *Table) Option {
+	return func(c *config) error {
+		if t == nil {
+			return optionError("WithTable", "the table is nil")
+		}
+		c.table = t
+		return nil
+	}
+}
+
+// WithSeed hashes with a table derived from the selected table, TableV1 by
+// default, as by Table.Seeded.
+func WithSeed(seed uint64) Option {
+	return func(c *config) error {
+		c.seeded = true
+		c.seed = seed
+		return nil
+	}
+}
+
+// WithCompat reproduces the window hashes of another buzhash implementation.
+// It cannot be combined with WithTable, WithSeed or WithBackend.
+func WithCompat(compat *Compat) Option {
+	return func(c *config) error {
+		if compat == nil {
+			return optionError("WithCompat", "the compatibility mode is nil")
+		}
+		c.compat = compat
+		return nil
+	}
+}
+
+// WithBackend runs the bulk loops on the given backend instead of following
+// DefaultBackend, like Hasher.SetBackend.
+func WithBackend(b *Backend) Option {
+	return func(c *config) error {
+		if b == nil {
+			return optionError("WithBackend", "the backend is nil")
+		}
+		c.backend = b
+		return nil
+	}
+}
+
+// WithOutputBits keeps only the low bits of every hash returned, e.g. to
+// store hashes in 32 bits. Rolling still uses the full state, so the result
+// equals the full hash masked to the width.
+func WithOutputBits(bits uint) Option {
+	return func(c *config) error {
+		if bits < 1 || bits > 64 {
+			return optionError("WithOutputBits", "%d is not between 1 and 64", bits)
+		}
+		c.outputBits = bits
+		return nil
+	}
+}
+
+// NewWithOptions is New with options selecting the table, backend and output
+// width; without them it equals New, hashing with TableV1 and returning full
+// 64-bit hashes. New keeps its signature so that it still fits where a
+// func([]byte, uint32) (RollingHash, error) is expected.
+func NewWithOptions(buf []byte, windowSize uint32, opts ...Option) (RollingHash, error) {
+	var c config
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return nil, err
+		}
+	}
+
+	var outMask uint64
+	if c.outputBits > 0 && c.outputBits < 64 {
+		outMask = 1<<c.outputBits - 1
+	}
+
+	if c.compat != nil {
+		switch {
+		case c.table != nil:
+			return nil, optionError("WithCompat", "cannot be combined with WithTable")
+		case c.seeded:
+			return nil, optionError("WithCompat", "cannot be combined with WithSeed")
+		case c.backend != nil:
+			return nil, optionError("WithCompat", "cannot be combined with WithBackend")
+		}
+
+		h, err := c.compat.New(buf, windowSize)
+		if err != nil {
+			return nil, err
+		}
+		h.(*CompatHasher).outMask = outMask
+		return h, nil
+	}
+
+	t := c.table
+	if t == nil {
+		t = tableV1
+	}
+	if c.seeded {
+		t = t.Seeded(c.seed)
+	}
+
+	h, err := t.New(buf, windowSize)
+	if err != nil {
+		return nil, err
+	}
+
+	hh := h.(*Hasher)
+	hh.backend = c.backend
+	hh.outMask = outMask
+	return hh, nil
+}
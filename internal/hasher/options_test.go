@@ -0,0 +1,167 @@
+package hasher
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWithoutOptions(t *testing.T) {
+	// New keeps the signature it had before options existed.
+	var newFunc func([]byte, uint32) (RollingHash, error) = New
+
+	h, err := newFunc([]byte(fox), 8)
+	assert.NoError(t, err)
+	withOptions, err := NewWithOptions([]byte(fox), 8)
+	assert.NoError(t, err)
+	assert.Equal(t, h, withOptions)
+
+	hh := withOptions.(*Hasher)
+	assert.Equal(t, tableV1, hh.Table())
+	assert.Nil(t, hh.backend)
+	assert.Zero(t, hh.outMask)
+}
+
+func TestWithTableAndSeed(t *testing.T) {
+	buf := []byte(fox)
+
+	h, err := NewWithOptions(buf, 8, WithTable(tableV1))
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(buf[:8]), h.Sum64())
+
+	seeded, err := NewWithOptions(buf, 8, WithSeed(42))
+	assert.NoError(t, err)
+	// The seeded table never reports plain TableV1, which would mislabel
+	// persisted hashes.
+	tbl := seeded.(*Hasher).Table()
+	assert.NotEqual(t, TableV1, tbl.Version())
+	assert.Equal(t, TableV1|TableSeeded, tbl.Version())
+	seed, ok := tbl.Seed()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(42), seed)
+	assert.NotEqual(t, h.Sum64(), seeded.Sum64())
+
+	// Seeded tables are deterministic and roll like any other table.
+	assert.Equal(t, tableV1.Seeded(42).Hash(buf[:8]), seeded.Sum64())
+	hashes, err := seeded.BulkRoll(1)
+	assert.NoError(t, err)
+	for i, hash := range hashes {
+		assert.Equal(t, tbl.Hash(buf[i:i+8]), hash)
+	}
+
+	_, ok = tableV1.Seed()
+	assert.False(t, ok)
+	assert.NotEqual(t, tableV1.Seeded(1).Hash(buf), tableV1.Seeded(2).Hash(buf))
+
+	// Version and seed together identify a seeded table, and reseeding
+	// starts over from the published table.
+	assert.Equal(t, tbl.Version(), tableV1.Seeded(7).Version())
+	assert.Equal(t, tableV1.Seeded(7).Hash(buf), tbl.Seeded(7).Hash(buf))
+	seed, _ = tbl.Seeded(7).Seed()
+	assert.Equal(t, uint64(7), seed)
+	assert.Equal(t, TableV1|TableSeeded, tbl.Seeded(7).Version())
+	_, err = LookupTable(tbl.Version())
+	assert.ErrorIs(t, err, ErrUnknownTable)
+}
+
+func TestWithBackend(t *testing.T) {
+	h, err := NewWithOptions([]byte(fox), 8, WithBackend(goBackend))
+	assert.NoError(t, err)
+	assert.Equal(t, goBackend, h.(*Hasher).Backend())
+}
+
+func TestWithOutputBits(t *testing.T) {
+	buf := make([]byte, 500)
+	rand.New(rand.NewSource(6)).Read(buf)
+
+	full, err := New(buf, 16)
+	assert.NoError(t, err)
+	h, err := NewWithOptions(buf, 16, WithOutputBits(20))
+	assert.NoError(t, err)
+	const mask = 1<<20 - 1
+
+	assert.Equal(t, full.Sum64()&mask, h.Sum64())
+	want, err := full.BulkRoll(3)
+	assert.NoError(t, err)
+	got, err := h.BulkRoll(3)
+	assert.NoError(t, err)
+	for i := range want {
+		assert.Equal(t, want[i]&mask, got[i])
+	}
+
+	positions, hashes, err := BulkRollMatch(h, 0xff, 0x2a, 1)
+	assert.NoError(t, err)
+	wantPositions, _, err := BulkRollMatch(full, 0xff, 0x2a, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, wantPositions, positions)
+	for _, hash := range hashes {
+		assert.Zero(t, hash&^mask)
+	}
+
+	// Bits beyond the output width never match.
+	positions, _, err = BulkRollMatch(h, 1<<40, 1<<40, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, positions)
+
+	err = BulkRollFunc(h, 1, func(_ uint32, hash uint64) bool {
+		assert.Zero(t, hash&^mask)
+		return true
+	})
+	assert.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		hash, err := h.Roll(1)
+		assert.NoError(t, err)
+		_, err = full.Roll(1)
+		assert.NoError(t, err)
+		assert.Equal(t, full.Sum64()&mask, hash)
+	}
+
+	// The width survives ResetTo, and 64 bits is the same as no option.
+	assert.NoError(t, h.(*Hasher).ResetTo(buf, 4))
+	assert.Equal(t, Hash(buf[:4])&mask, h.Sum64())
+	h64, err := NewWithOptions(buf, 16, WithOutputBits(64))
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(buf[:16]), h64.Sum64())
+}
+
+func TestWithCompat(t *testing.T) {
+	buf := []byte(fox)
+	h, err := NewWithOptions(buf, 8, WithCompat(CompatSilvasur), WithOutputBits(16))
+	assert.NoError(t, err)
+	assert.Equal(t, CompatSilvasur, h.(*CompatHasher).Compat())
+	assert.Equal(t, CompatSilvasur.Hash(buf[:8])&0xffff, h.Sum64())
+
+	hash, err := h.Roll(1)
+	assert.NoError(t, err)
+	assert.Equal(t, CompatSilvasur.Hash(buf[1:9])&0xffff, hash)
+}
+
+func TestOptionErrors(t *testing.T) {
+	buf := []byte(fox)
+	cases := []struct {
+		opts []Option
+		name string
+	}{
+		{[]Option{WithTable(nil)}, "WithTable"},
+		{[]Option{WithBackend(nil)}, "WithBackend"},
+		{[]Option{WithCompat(nil)}, "WithCompat"},
+		{[]Option{WithOutputBits(0)}, "WithOutputBits"},
+		{[]Option{WithOutputBits(65)}, "WithOutputBits"},
+		{[]Option{WithCompat(CompatSilvasur), WithSeed(1)}, "WithSeed"},
+		{[]Option{WithTable(tableV1), WithCompat(CompatSilvasur)}, "WithTable"},
+		{[]Option{WithCompat(CompatSilvasur), WithBackend(goBackend)}, "WithBackend"},
+	}
+
+	for _, c := range cases {
+		_, err := NewWithOptions(buf, 8, c.opts...)
+		assert.ErrorIs(t, err, ErrIllegalOption)
+		assert.ErrorContains(t, err, c.name)
+	}
+
+	_, err := NewWithOptions(buf, 100, WithSeed(1))
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+	_, err = NewWithOptions(buf, 100, WithCompat(CompatSilvasur))
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+}
@@ -0,0 +1,283 @@
+package hasher
+
+import (
+	"errors"
+	"io"
+	"math/bits"
+)
+
+var ErrIllegalSeek = errors.New("cannot seek outside of the input")
+var ErrIllegalPaging = errors.New("the page cache needs at least 2 pages of at least 1 byte")
+
+const (
+	defaultPageSize  = 64 << 10
+	defaultPageCount = 4
+)
+
+// PagedOptions configures the page cache of a PagedHasher. Zero values pick
+// the defaults of 4 pages of 64 KiB.
+type PagedOptions struct {
+	// The size of every page in bytes
+	PageSize int
+	// The number of cached pages, at least 2 so the outgoing and incoming
+	// bytes of a window can be held at the same time
+	Pages int
+}
+
+// A cached page of input.
+type page struct {
+	// The index of the page in the input, -1 when unused
+	index int64
+	// The bytes of the page, shorter than the page size at the end of input
+	data []byte
+	// The tick of the last access for LRU eviction
+	used uint64
+}
+
+// Implements a rolling hash over an io.ReaderAt of known size, such as a
+// block device or remote storage, that may be larger than memory. Only a
+// fixed number of pages are cached, so memory is bounded by the options and
+// not by the input size. Positions are int64 offsets into the input, which is
+// why it does not implement RollingHash. Hashes are identical to a Hasher
+// over the same bytes.
+//
+// Read errors are returned as they happen. A failed Roll leaves the hasher at
+// the last window it fully hashed.
+type PagedHasher struct {
+	// The input to hash over
+	r io.ReaderAt
+	// The size of the input
+	size int64
+	// The window size for calculating the hash
+	windowSize uint32
+	// The current window start position
+	position int64
+	// The current pre-computed hash
+	hash uint64
+	// The size of every page
+	pageSize int64
+	// The page cache
+	pages []page
+	// Advances on every page access
+	tick uint64
+	// The cache slots last used for outgoing and incoming bytes
+	outHint, inHint int
+}
+
+// Creates a new paged rolling hasher over the first size bytes of r with the
+// window starting from 0 index.
+func NewPaged(r io.ReaderAt, size int64, windowSize uint32, opts PagedOptions) (*PagedHasher, error) {
+	if opts.PageSize == 0 {
+		opts.PageSize = defaultPageSize
+	}
+	if opts.Pages == 0 {
+		opts.Pages = defaultPageCount
+	}
+	if opts.PageSize < 1 || opts.Pages < 2 {
+		return nil, ErrIllegalPaging
+	}
+	if size < 0 || int64(windowSize) > size {
+		return nil, ErrWindowTooLong
+	}
+
+	h := &PagedHasher{
+		r:          r,
+		size:       size,
+		windowSize: windowSize,
+		pageSize:   int64(opts.PageSize),
+		pages:      make([]page, opts.Pages),
+	}
+	for i := range h.pages {
+		h.pages[i].index = -1
+	}
+
+	if _, err := h.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Returns the byte at the offset, reading its page into the cache when
+// needed. The hint remembers the slot of the last page used by the caller.
+func (h *PagedHasher) byteAt(off int64, hint *int) (byte, error) {
+	index := off / h.pageSize
+	h.tick++
+
+	if p := &h.pages[*hint]; p.index == index {
+		p.used = h.tick
+		return p.data[off-index*h.pageSize], nil
+	}
+
+	lru := 0
+	for i := range h.pages {
+		p := &h.pages[i]
+		if p.index == index {
+			p.used = h.tick
+			*hint = i
+			return p.data[off-index*h.pageSize], nil
+		}
+		if p.used < h.pages[lru].used {
+			lru = i
+		}
+	}
+
+	p := &h.pages[lru]
+	start := index * h.pageSize
+	n := min(h.pageSize, h.size-start)
+	if cap(p.data) < int(n) {
+		p.data = make([]byte, h.pageSize)
+	}
+	p.data = p.data[:n]
+
+	// Mark the slot unused until the read succeeds.
+	p.index = -1
+	read, err := h.r.ReadAt(p.data, start)
+	if int64(read) < n {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+
+	p.index = index
+	p.used = h.tick
+	*hint = lru
+	return p.data[off-start], nil
+}
+
+// Rolls the hasing window by the given step. Changes the window start position.
+func (h *PagedHasher) Roll(step uint32) (uint64, error) {
+	if h.position+int64(step)+int64(h.windowSize) > h.size {
+		return 0, ErrIllegalRoll
+	}
+
+	for i := uint32(0); i < step; i++ {
+		hash, err := h.next(h.position, h.hash)
+		if err != nil {
+			return 0, err
+		}
+		h.hash = hash
+		h.position++
+	}
+
+	return h.hash, nil
+}
+
+// Returns the hash of the window after pos given the hash of the window at
+// pos.
+func (h *PagedHasher) next(pos int64, hash uint64) (uint64, error) {
+	out, err := h.byteAt(pos, &h.outHint)
+	if err != nil {
+		return 0, err
+	}
+	in, err := h.byteAt(pos+int64(h.windowSize), &h.inHint)
+	if err != nil {
+		return 0, err
+	}
+
+	return bits.RotateLeft64(hash, 1) ^
+		bits.RotateLeft64(table[out], int(h.windowSize)) ^
+		table[in], nil
+}
+
+// BulkRoll rolls over the window at the given stride and returns all hashes.
+// Does not change the window starting position.
+func (h *PagedHasher) BulkRoll(stride uint32) ([]uint64, error) {
+	if stride == 0 {
+		return nil, ErrIllegalStride
+	}
+
+	hashes := make([]uint64, 0, (h.size-int64(h.windowSize)-h.position)/int64(stride)+1)
+	err := h.BulkRollFunc(stride, func(_ int64, hash uint64) bool {
+		hashes = append(hashes, hash)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// BulkRollFunc calls fn with the position and hash of every window at the
+// given stride until fn returns false, without materializing the hashes.
+// Does not change the window starting position.
+func (h *PagedHasher) BulkRollFunc(stride uint32, fn func(pos int64, h uint64) bool) error {
+	if stride == 0 {
+		return ErrIllegalStride
+	}
+
+	pos := h.position
+	hash := h.hash
+	var err error
+
+	for pos+int64(h.windowSize) <= h.size {
+		if !fn(pos, hash) {
+			return nil
+		}
+
+		for i := uint32(0); i < stride; i++ {
+			if pos+int64(h.windowSize) >= h.size {
+				return nil
+			}
+
+			hash, err = h.next(pos, hash)
+			if err != nil {
+				return err
+			}
+			pos++
+		}
+	}
+
+	return nil
+}
+
+// Seek implements io.Seeker by moving the window to start at the offset
+// relative to whence, and rehashes the whole window. Seeking to
+// -windowSize relative to io.SeekEnd moves to the last window.
+func (h *PagedHasher) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += h.position
+	case io.SeekEnd:
+		offset += h.size
+	default:
+		return 0, ErrIllegalSeek
+	}
+	if offset < 0 || offset+int64(h.windowSize) > h.size {
+		return 0, ErrIllegalSeek
+	}
+
+	var hash uint64
+	n := int64(h.windowSize)
+	for i := int64(0); i < n; i++ {
+		b, err := h.byteAt(offset+i, &h.inHint)
+		if err != nil {
+			return 0, err
+		}
+		hash ^= bits.RotateLeft64(table[b], int(n-1-i))
+	}
+
+	h.position = offset
+	h.hash = hash
+
+	return offset, nil
+}
+
+// Get the hash value of the current state of the hasher. Does not change the
+// state in any way.
+func (h *PagedHasher) Sum64() uint64 {
+	return h.hash
+}
+
+// Get the current position in the input
+func (h *PagedHasher) Position() int64 {
+	return h.position
+}
+
+// Table returns the table the hasher hashes with, always TableV1.
+func (h *PagedHasher) Table() *Table {
+	return tableV1
+}
@@ -0,0 +1,166 @@
+package hasher
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Counts reads and fails at failAt when set.
+type countingReader struct {
+	r      io.ReaderAt
+	reads  int
+	failAt int64
+}
+
+var errRead = errors.New("read failed")
+
+func (c *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	c.reads++
+	if c.failAt > 0 && off <= c.failAt && c.failAt < off+int64(len(p)) {
+		return 0, errRead
+	}
+	return c.r.ReadAt(p, off)
+}
+
+func pagedInput(n int) []byte {
+	buf := make([]byte, n)
+	rand.New(rand.NewSource(3)).Read(buf)
+	return buf
+}
+
+func TestPagedMatchesHasher(t *testing.T) {
+	buf := pagedInput(5000)
+
+	for _, opts := range []PagedOptions{{}, {PageSize: 7, Pages: 2}, {PageSize: 100, Pages: 3}, {PageSize: 4096, Pages: 2}} {
+		for _, windowSize := range []uint32{0, 1, 16, 64, 250} {
+			p, err := NewPaged(bytes.NewReader(buf), int64(len(buf)), windowSize, opts)
+			assert.NoError(t, err)
+			h, err := New(buf, windowSize)
+			assert.NoError(t, err)
+			assert.Equal(t, h.Sum64(), p.Sum64())
+
+			for _, stride := range []uint32{1, 5} {
+				want, err := h.BulkRoll(stride)
+				assert.NoError(t, err)
+				got, err := p.BulkRoll(stride)
+				assert.NoError(t, err)
+				assert.Equal(t, want, got, "opts %+v window %d stride %d", opts, windowSize, stride)
+			}
+
+			for {
+				want, werr := h.Roll(3)
+				got, gerr := p.Roll(3)
+				assert.Equal(t, werr, gerr)
+				if werr != nil {
+					break
+				}
+				assert.Equal(t, want, got)
+				assert.Equal(t, int64(h.Position()), p.Position())
+			}
+		}
+	}
+}
+
+func TestPagedBoundedReads(t *testing.T) {
+	buf := pagedInput(10000)
+	r := &countingReader{r: bytes.NewReader(buf)}
+
+	// The window spans several pages, so the outgoing and incoming bytes
+	// live on different pages for the whole roll.
+	p, err := NewPaged(r, int64(len(buf)), 300, PagedOptions{PageSize: 100, Pages: 2})
+	assert.NoError(t, err)
+
+	_, err = p.Roll(uint32(len(buf) - 300))
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(buf[len(buf)-300:]), p.Sum64())
+
+	// Every page is read once for the initial window, once for outgoing and
+	// once for incoming bytes.
+	assert.LessOrEqual(t, r.reads, 3*len(buf)/100)
+}
+
+func TestPagedSeek(t *testing.T) {
+	buf := pagedInput(1000)
+	p, err := NewPaged(bytes.NewReader(buf), int64(len(buf)), 32, PagedOptions{PageSize: 50, Pages: 2})
+	assert.NoError(t, err)
+
+	pos, err := p.Seek(500, io.SeekStart)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), pos)
+	assert.Equal(t, Hash(buf[500:532]), p.Sum64())
+
+	pos, err = p.Seek(-100, io.SeekCurrent)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(400), pos)
+	assert.Equal(t, Hash(buf[400:432]), p.Sum64())
+
+	hash, err := p.Roll(1)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(buf[401:433]), hash)
+
+	pos, err = p.Seek(-32, io.SeekEnd)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(968), pos)
+	assert.Equal(t, Hash(buf[968:]), p.Sum64())
+	_, err = p.Roll(1)
+	assert.ErrorIs(t, err, ErrIllegalRoll)
+
+	for _, off := range []int64{-1, 969} {
+		_, err = p.Seek(off, io.SeekStart)
+		assert.ErrorIs(t, err, ErrIllegalSeek)
+	}
+	_, err = p.Seek(0, 42)
+	assert.ErrorIs(t, err, ErrIllegalSeek)
+	assert.Equal(t, int64(968), p.Position())
+}
+
+func TestPagedBulkRollFunc(t *testing.T) {
+	buf := pagedInput(200)
+	p, err := NewPaged(bytes.NewReader(buf), int64(len(buf)), 10, PagedOptions{PageSize: 16, Pages: 2})
+	assert.NoError(t, err)
+
+	var positions []int64
+	err = p.BulkRollFunc(50, func(pos int64, hash uint64) bool {
+		assert.Equal(t, Hash(buf[pos:pos+10]), hash)
+		positions = append(positions, pos)
+		return len(positions) < 3
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{0, 50, 100}, positions)
+	assert.Equal(t, int64(0), p.Position())
+
+	_, err = p.BulkRoll(0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+}
+
+func TestPagedReadErrors(t *testing.T) {
+	buf := pagedInput(1000)
+	r := &countingReader{r: bytes.NewReader(buf), failAt: 600}
+	p, err := NewPaged(r, int64(len(buf)), 20, PagedOptions{PageSize: 64, Pages: 2})
+	assert.NoError(t, err)
+
+	_, err = p.BulkRoll(1)
+	assert.ErrorIs(t, err, errRead)
+
+	// The failed roll stops at the last window it could hash.
+	_, err = p.Roll(700)
+	assert.ErrorIs(t, err, errRead)
+	assert.Equal(t, Hash(buf[p.Position():p.Position()+20]), p.Sum64())
+	assert.Less(t, p.Position(), int64(600))
+
+	// An input shorter than the declared size is reported too.
+	_, err = NewPaged(bytes.NewReader(buf[:10]), 100, 20, PagedOptions{})
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, err = NewPaged(r, 1000, 20, PagedOptions{Pages: 1})
+	assert.ErrorIs(t, err, ErrIllegalPaging)
+	_, err = NewPaged(r, 1000, 20, PagedOptions{PageSize: -1})
+	assert.ErrorIs(t, err, ErrIllegalPaging)
+	_, err = NewPaged(r, 10, 20, PagedOptions{})
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+}
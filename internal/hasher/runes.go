@@ -0,0 +1,161 @@
+package hasher
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var ErrInvalidUTF8 = errors.New("the input is not valid UTF-8")
+
+// InvalidUTF8 decides how RuneHasher treats bytes that are not valid UTF-8.
+type InvalidUTF8 int
+
+const (
+	// Every invalid byte becomes one U+FFFD rune, like ranging over a string.
+	ReplaceInvalid InvalidUTF8 = iota
+	// NewRunes fails with ErrInvalidUTF8.
+	RejectInvalid
+)
+
+// RuneOptions configures a RuneHasher.
+type RuneOptions struct {
+	// Seeds the mapping from runes to 64-bit values
+	Seed uint64
+	// The handling of invalid UTF-8
+	Invalid InvalidUTF8
+	// Optionally rewrites the input before hashing, e.g. norm.NFC.Bytes from
+	// golang.org/x/text for Unicode normalization. Byte offsets then refer to
+	// the normalized text returned by Bytes, not to the input: the rewrite
+	// is opaque, so they cannot be mapped back. The normalize package tracks
+	// the original offsets for the normalizations it implements.
+	Normalize func([]byte) []byte
+}
+
+// A window of a RuneHasher with its byte offsets in the hashed text, which
+// is the normalized text from Bytes rather than the input when
+// RuneOptions.Normalize is set.
+type RuneWindow struct {
+	// The index of the first rune of the window
+	Position uint32
+	// The byte offset of the first rune
+	Start uint32
+	// The byte offset just past the last rune
+	End  uint32
+	Hash uint64
+}
+
+// Implements RollingHash over the runes of UTF-8 text, so windows never
+// split a code point. The window size and all positions count runes, and the
+// byte offsets of any window are available through WindowOffsets.
+//
+// It is a SymbolHasher over the decoded runes, so runes are mapped to 64-bit
+// values with SeededMapper instead of the byte table and then combined with
+// the same rotation scheme as Hasher.
+type RuneHasher struct {
+	SymbolHasher[rune]
+	// The hashed text after normalization
+	text []byte
+	// The byte offset of every rune followed by len(text)
+	offsets []uint32
+}
+
+// Creates a new rune-level rolling hasher over the UTF-8 text with the
+// window starting from the first rune. With RuneOptions.Normalize set, runes,
+// positions and byte offsets all refer to the normalized text from Bytes and
+// cannot be used to slice buf.
+func NewRunes(buf []byte, windowSize uint32, opts RuneOptions) (*RuneHasher, error) {
+	return newRunes(buf, windowSize, false, opts)
+}
+
+// Decodes the text after normalization. With whole set, the window covers
+// every rune of the normalized text and windowSize is ignored, since a
+// normalizer may change the rune count.
+func newRunes(buf []byte, windowSize uint32, whole bool, opts RuneOptions) (*RuneHasher, error) {
+	if opts.Normalize != nil {
+		buf = opts.Normalize(buf)
+	}
+
+	count := utf8.RuneCount(buf)
+	runes := make([]rune, 0, count)
+	offsets := make([]uint32, 0, count+1)
+
+	for i := 0; i < len(buf); {
+		r, size := utf8.DecodeRune(buf[i:])
+		if r == utf8.RuneError && size == 1 && opts.Invalid == RejectInvalid {
+			return nil, ErrInvalidUTF8
+		}
+
+		runes = append(runes, r)
+		offsets = append(offsets, uint32(i))
+		i += size
+	}
+	offsets = append(offsets, uint32(len(buf)))
+
+	if whole {
+		windowSize = uint32(len(runes))
+	}
+
+	symbols, err := NewSymbols(runes, windowSize, SeededMapper[rune](opts.Seed))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RuneHasher{
+		SymbolHasher: *symbols,
+		text:         buf,
+		offsets:      offsets,
+	}, nil
+}
+
+// HashRunes hashes all runes of the text in one shot, equal to a RuneHasher
+// whose window covers the whole text after normalization.
+func HashRunes(buf []byte, opts RuneOptions) (uint64, error) {
+	h, err := newRunes(buf, 0, true, opts)
+	if err != nil {
+		return 0, err
+	}
+	return h.hash, nil
+}
+
+// WindowOffsets returns the byte offsets [start, end) in Bytes of the window
+// starting at the given rune position.
+func (h *RuneHasher) WindowOffsets(pos uint32) (uint32, uint32, error) {
+	if pos+h.windowSize > uint32(len(h.syms)) {
+		return 0, 0, ErrIllegalRange
+	}
+	return h.offsets[pos], h.offsets[pos+h.windowSize], nil
+}
+
+// Offsets returns the byte offsets [start, end) of the current window.
+func (h *RuneHasher) Offsets() (uint32, uint32) {
+	return h.offsets[h.position], h.offsets[h.position+h.windowSize]
+}
+
+// Bytes returns the hashed text, which differs from the input when a
+// normalizer is configured.
+func (h *RuneHasher) Bytes() []byte {
+	return h.text
+}
+
+// Clone implements Cloner. The text and offsets are shared.
+func (h *RuneHasher) Clone() RollingHash {
+	c := *h
+	return &c
+}
+
+// BulkRollWindows rolls like BulkRoll and also reports the byte offsets of
+// every window.
+func (h *RuneHasher) BulkRollWindows(stride uint32) ([]RuneWindow, error) {
+	var windows []RuneWindow
+	err := h.BulkRollFunc(stride, func(pos uint32, hash uint64) bool {
+		windows = append(windows, RuneWindow{
+			Position: pos,
+			Start:    h.offsets[pos],
+			End:      h.offsets[pos+h.windowSize],
+			Hash:     hash,
+		})
+		return true
+	})
+
+	return windows, err
+}
@@ -0,0 +1,166 @@
+package hasher
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var multilingual = []byte("Grüße aus Zürich, こんにちは世界, Привет мир, 🙂👍 done")
+
+func TestRuneRollMatchesOneShot(t *testing.T) {
+	for _, windowSize := range []uint32{1, 3, 6} {
+		h, err := NewRunes(multilingual, windowSize, RuneOptions{Seed: 7})
+		assert.NoError(t, err)
+
+		for {
+			start, end := h.Offsets()
+			window := multilingual[start:end]
+			assert.True(t, utf8.Valid(window))
+			assert.Equal(t, int(windowSize), utf8.RuneCount(window))
+
+			want, err := HashRunes(window, RuneOptions{Seed: 7})
+			assert.NoError(t, err)
+			assert.Equal(t, want, h.Sum64(), "window %q", window)
+
+			if _, err := h.Roll(1); err != nil {
+				assert.ErrorIs(t, err, ErrIllegalRoll)
+				break
+			}
+		}
+
+		assert.Equal(t, uint32(utf8.RuneCount(multilingual))-windowSize, h.Position())
+	}
+}
+
+func TestRuneBulkRoll(t *testing.T) {
+	h, err := NewRunes(multilingual, 4, RuneOptions{})
+	assert.NoError(t, err)
+	_, err = h.Roll(2)
+	assert.NoError(t, err)
+
+	hashes, err := h.BulkRoll(3)
+	assert.NoError(t, err)
+	windows, err := h.BulkRollWindows(3)
+	assert.NoError(t, err)
+	assert.Len(t, windows, len(hashes))
+
+	for i, w := range windows {
+		assert.Equal(t, uint32(2+3*i), w.Position)
+		assert.Equal(t, hashes[i], w.Hash)
+
+		start, end, err := h.WindowOffsets(w.Position)
+		assert.NoError(t, err)
+		assert.Equal(t, start, w.Start)
+		assert.Equal(t, end, w.End)
+
+		want, _ := HashRunes(multilingual[w.Start:w.End], RuneOptions{})
+		assert.Equal(t, want, w.Hash)
+	}
+
+	positions, matched, err := h.BulkRollMatch(0x1, 0x1, 3)
+	assert.NoError(t, err)
+	for i, pos := range positions {
+		assert.Equal(t, hashes[(pos-2)/3], matched[i])
+	}
+
+	calls := 0
+	err = h.BulkRollContext(context.Background(), 1, func(uint32, uint64) bool {
+		calls++
+		return calls < 2
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, uint32(2), h.Position())
+
+	_, err = h.BulkRoll(0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+	_, err = h.BulkRollWindows(0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+	_, _, err = h.WindowOffsets(1000)
+	assert.ErrorIs(t, err, ErrIllegalRange)
+}
+
+func TestRuneInvalidUTF8(t *testing.T) {
+	invalid := []byte("ab\xffcd\xe3\x81")
+
+	h, err := NewRunes(invalid, 3, RuneOptions{})
+	assert.NoError(t, err)
+	// Every invalid byte is a rune of its own.
+	windows, err := h.BulkRollWindows(1)
+	assert.NoError(t, err)
+	assert.Len(t, windows, 7-3+1)
+
+	replaced, _ := HashRunes([]byte("b�c"), RuneOptions{})
+	assert.Equal(t, replaced, windows[1].Hash)
+	assert.Equal(t, uint32(1), windows[1].Start)
+	assert.Equal(t, uint32(4), windows[1].End)
+
+	_, err = NewRunes(invalid, 3, RuneOptions{Invalid: RejectInvalid})
+	assert.ErrorIs(t, err, ErrInvalidUTF8)
+
+	_, err = NewRunes(multilingual, 3, RuneOptions{Invalid: RejectInvalid})
+	assert.NoError(t, err)
+}
+
+func TestRuneOptions(t *testing.T) {
+	a, _ := HashRunes([]byte("héllo"), RuneOptions{Seed: 1})
+	b, _ := HashRunes([]byte("héllo"), RuneOptions{Seed: 2})
+	assert.NotEqual(t, a, b)
+
+	h, err := NewRunes([]byte("HÉLLO"), 5, RuneOptions{Seed: 1, Normalize: bytes.ToLower})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("héllo"), h.Bytes())
+	assert.Equal(t, a, h.Sum64())
+
+	_, err = NewRunes([]byte("héllo"), 6, RuneOptions{})
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+
+	n, err := h.Write([]byte("x"))
+	assert.ErrorIs(t, err, ErrNotWritable)
+	assert.Zero(t, n)
+	assert.Equal(t, 8, h.Size())
+	assert.Equal(t, 1, h.BlockSize())
+	assert.Len(t, h.Sum(nil), 8)
+
+	h.Reset()
+	assert.Equal(t, a, h.Sum64())
+}
+
+func TestHashRunesNormalizedCount(t *testing.T) {
+	// Composing and decomposing accents changes the rune count of the text.
+	compose := func(b []byte) []byte {
+		return bytes.ReplaceAll(b, []byte("é"), []byte("é"))
+	}
+	decompose := func(b []byte) []byte {
+		return bytes.ReplaceAll(b, []byte("é"), []byte("é"))
+	}
+
+	for name, tc := range map[string]struct {
+		input     string
+		normalize func([]byte) []byte
+	}{
+		"shrinking": {"café déja", compose},
+		"expanding": {"café déja", decompose},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts := RuneOptions{Seed: 3, Normalize: tc.normalize}
+			normalized := tc.normalize([]byte(tc.input))
+			assert.NotEqual(t, utf8.RuneCountInString(tc.input), utf8.RuneCount(normalized))
+
+			hash, err := HashRunes([]byte(tc.input), opts)
+			assert.NoError(t, err)
+
+			h, err := NewRunes([]byte(tc.input), uint32(utf8.RuneCount(normalized)), opts)
+			assert.NoError(t, err)
+			assert.Equal(t, h.Sum64(), hash)
+
+			start, end := h.Offsets()
+			assert.Equal(t, uint32(0), start)
+			assert.Equal(t, uint32(len(normalized)), end)
+		})
+	}
+}
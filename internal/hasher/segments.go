@@ -0,0 +1,216 @@
+package hasher
+
+import (
+	"context"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Implements RollingHash over a list of segments, e.g. net.Buffers, as if
+// they were concatenated but without copying them. Windows span segment
+// boundaries transparently and all positions are offsets into the
+// concatenation. Like Hasher, the segments can never be mutated.
+type SegmentedHasher struct {
+	// The non-empty segments to hash over
+	segs [][]byte
+	// The global offset of the first byte of every segment
+	starts []uint32
+	// The total number of bytes
+	n uint32
+	// The window size for calculating the hash
+	windowSize uint32
+	// The current window start position
+	position uint32
+	// The current pre-computed hash
+	hash uint64
+	// The byte leaving the window on the next roll
+	out segmentCursor
+	// The byte entering the window on the next roll
+	in segmentCursor
+}
+
+// Points at a byte within the segments.
+type segmentCursor struct {
+	seg int
+	off int
+}
+
+// Creates a new rolling hasher over the concatenation of the segments with
+// the window starting from 0 index.
+func NewSegmented(segs [][]byte, windowSize uint32) (RollingHash, error) {
+	h := &SegmentedHasher{windowSize: windowSize}
+	for _, s := range segs {
+		if len(s) == 0 {
+			continue
+		}
+		h.segs = append(h.segs, s)
+		h.starts = append(h.starts, h.n)
+		h.n += uint32(len(s))
+	}
+
+	if windowSize > h.n {
+		return nil, ErrWindowTooLong
+	}
+
+	h.Reset()
+	return h, nil
+}
+
+func (h *SegmentedHasher) at(c segmentCursor) byte {
+	return h.segs[c.seg][c.off]
+}
+
+func (h *SegmentedHasher) advance(c *segmentCursor) {
+	c.off++
+	if c.off == len(h.segs[c.seg]) {
+		c.seg++
+		c.off = 0
+	}
+}
+
+// Rolls the hasing window by the given step. Changes the window start position.
+func (h *SegmentedHasher) Roll(step uint32) (uint64, error) {
+	if h.position+step+h.windowSize > h.n {
+		return 0, ErrIllegalRoll
+	}
+
+	for i := uint32(0); i < step; i++ {
+		h.hash = bits.RotateLeft64(h.hash, 1) ^
+			bits.RotateLeft64(table[h.at(h.out)], int(h.windowSize)) ^
+			table[h.at(h.in)]
+
+		h.advance(&h.out)
+		h.advance(&h.in)
+		h.position++
+	}
+
+	return h.hash, nil
+}
+
+// BulkRoll implements RollingHash.
+func (h *SegmentedHasher) BulkRoll(stride uint32) ([]uint64, error) {
+	if stride == 0 {
+		return nil, ErrIllegalStride
+	}
+
+	hashes := make([]uint64, 0, (h.n-h.windowSize-h.position)/stride+1)
+	err := h.BulkRollFunc(stride, func(_ uint32, hash uint64) bool {
+		hashes = append(hashes, hash)
+		return true
+	})
+
+	return hashes, err
+}
+
+// BulkRollMatch implements MatchRoller.
+func (h *SegmentedHasher) BulkRollMatch(mask, target uint64, stride uint32) ([]uint32, []uint64, error) {
+	var positions []uint32
+	var hashes []uint64
+	err := h.BulkRollFunc(stride, func(pos uint32, hash uint64) bool {
+		if hash&mask == target {
+			positions = append(positions, pos)
+			hashes = append(hashes, hash)
+		}
+		return true
+	})
+
+	return positions, hashes, err
+}
+
+// BulkRollFunc implements FuncRoller.
+func (h *SegmentedHasher) BulkRollFunc(stride uint32, fn func(pos uint32, h uint64) bool) error {
+	if stride == 0 {
+		return ErrIllegalStride
+	}
+
+	pos := h.position
+	hash := h.hash
+	out, in := h.out, h.in
+
+	for pos+h.windowSize <= h.n {
+		if !fn(pos, hash) {
+			return nil
+		}
+
+		for i := uint32(0); i < stride; i++ {
+			if pos+h.windowSize >= h.n {
+				return nil
+			}
+
+			hash = bits.RotateLeft64(hash, 1) ^
+				bits.RotateLeft64(table[h.at(out)], int(h.windowSize)) ^
+				table[h.at(in)]
+
+			h.advance(&out)
+			h.advance(&in)
+			pos++
+		}
+	}
+
+	return nil
+}
+
+// BulkRollContext implements FuncRoller.
+func (h *SegmentedHasher) BulkRollContext(ctx context.Context, stride uint32, fn func(pos uint32, h uint64) bool) error {
+	return bulkRollContext(ctx, stride, fn, h.BulkRollFunc)
+}
+
+// Get the hash value of the current state of the hasher. Does not change the
+// state in any way.
+func (h *SegmentedHasher) Sum64() uint64 {
+	return h.hash
+}
+
+// Sum appends the current hash to b and returns the resulting slice.
+// It does not change the underlying hash state.
+func (h *SegmentedHasher) Sum(b []byte) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], h.Sum64())
+	return append(b, buf[:]...)
+}
+
+// Reset the position of this hasher.
+func (h *SegmentedHasher) Reset() {
+	h.position = 0
+	h.out = segmentCursor{}
+	h.hash = 0
+
+	c := segmentCursor{}
+	for i := uint32(0); i < h.windowSize; i++ {
+		h.hash ^= bits.RotateLeft64(table[h.at(c)], int(h.windowSize-1-i))
+		h.advance(&c)
+	}
+	h.in = c
+}
+
+// Size returns the number of bytes Sum will return.
+func (h *SegmentedHasher) Size() int {
+	return hashSizeBytes
+}
+
+// Not implemented and not applicable for this hash. The bytes are passed
+// only with NewSegmented and never updated.
+func (h *SegmentedHasher) Write(p []byte) (int, error) {
+	return 0, ErrNotWritable
+}
+
+// In buzhash context, a block size doesn't have any impact
+func (h *SegmentedHasher) BlockSize() int {
+	return 1
+}
+
+// Get the current position in the input
+func (h *SegmentedHasher) Position() uint32 {
+	return h.position
+}
+
+// Clone implements Cloner. The segments are shared.
+func (h *SegmentedHasher) Clone() RollingHash {
+	c := *h
+	return &c
+}
+
+// Table returns the table the hasher hashes with, always TableV1.
+func (h *SegmentedHasher) Table() *Table {
+	return tableV1
+}
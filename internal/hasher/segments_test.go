@@ -0,0 +1,112 @@
+package hasher
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Splits data into random segments, including empty ones.
+func randomSegments(rng *rand.Rand, data []byte) [][]byte {
+	var segs [][]byte
+	for len(data) > 0 {
+		n := min(rng.Intn(8), len(data))
+		segs = append(segs, data[:n])
+		data = data[n:]
+	}
+	return segs
+}
+
+func TestSegmentedMatchesContiguous(t *testing.T) {
+	rng := rand.New(rand.NewSource(23))
+
+	for trial := 0; trial < 50; trial++ {
+		data := make([]byte, 1+rng.Intn(100))
+		rng.Read(data)
+		windowSize := uint32(1 + rng.Intn(len(data)))
+		segs := randomSegments(rng, data)
+
+		seg, err := NewSegmented(segs, windowSize)
+		assert.NoError(t, err)
+		flat, err := New(data, windowSize)
+		assert.NoError(t, err)
+		assert.Equal(t, flat.Sum64(), seg.Sum64())
+
+		for stride := uint32(1); stride <= 3; stride++ {
+			want, _ := flat.BulkRoll(stride)
+			got, err := seg.BulkRoll(stride)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+
+			wantPos, wantHashes, _ := BulkRollMatch(flat, 0x3, 0x1, stride)
+			gotPos, gotHashes, err := BulkRollMatch(seg, 0x3, 0x1, stride)
+			assert.NoError(t, err)
+			assert.Equal(t, wantPos, gotPos)
+			assert.Equal(t, wantHashes, gotHashes)
+		}
+
+		for {
+			want, wantErr := flat.Roll(1)
+			got, err := seg.Roll(1)
+			assert.Equal(t, wantErr, err)
+			if err != nil {
+				break
+			}
+			assert.Equal(t, want, got)
+			assert.Equal(t, flat.Position(), seg.Position())
+		}
+
+		seg.Reset()
+		assert.Equal(t, Hash(data[:windowSize]), seg.Sum64())
+	}
+}
+
+func TestSegmentedNetBuffers(t *testing.T) {
+	bufs := net.Buffers{[]byte("hello "), nil, []byte("w"), []byte("orld, how are you")}
+	data := bytes.Join(bufs, nil)
+
+	h, err := NewSegmented(bufs, 5)
+	assert.NoError(t, err)
+
+	hash, err := h.Roll(4)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(data[4:9]), hash)
+	assert.Equal(t, uint32(4), h.Position())
+
+	var positions []uint32
+	err = BulkRollContext(context.Background(), h, 5, func(pos uint32, hash uint64) bool {
+		assert.Equal(t, Hash(data[pos:pos+5]), hash)
+		positions = append(positions, pos)
+		return pos < 10
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{4, 9, 14}, positions)
+	assert.Equal(t, uint32(4), h.Position(), "BulkRollContext should not mutate internal state")
+
+	_, err = h.Roll(uint32(len(data)))
+	assert.ErrorIs(t, err, ErrIllegalRoll)
+	_, err = h.BulkRoll(0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+	_, _, err = BulkRollMatch(h, 0, 0, 0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+}
+
+func TestSegmentedErrors(t *testing.T) {
+	_, err := NewSegmented([][]byte{[]byte("ab"), []byte("c")}, 4)
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+
+	h, err := NewSegmented(nil, 0)
+	assert.NoError(t, err)
+	assert.Zero(t, h.Sum64())
+
+	n, err := h.Write([]byte("x"))
+	assert.ErrorIs(t, err, ErrNotWritable)
+	assert.Zero(t, n)
+	assert.Equal(t, 8, h.Size())
+	assert.Equal(t, 1, h.BlockSize())
+	assert.Len(t, h.Sum(nil), 8)
+}
@@ -0,0 +1,323 @@
+package hasher
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+var ErrIllegalSeed = errors.New("a spaced seed must only contain '0' and '1' and start and end with '1'")
+
+// A spaced seed selects which positions of a window contribute to its hash,
+// e.g. "1101101" ignores the third and sixth bytes. The hash of a window
+// equals Hash over the bytes at the '1' positions.
+//
+// The seed is split into blocks of consecutive '1' positions. Each block is
+// an ordinary contiguous buzhash window rolled independently, and the window
+// hash combines them rotated by the number of selected bytes that follow
+// the block. Rolling therefore costs O(blocks) rather than O(weight).
+type SpacedSeed struct {
+	// The textual pattern the seed was parsed from
+	pattern string
+	// The number of bytes a window spans
+	span uint32
+	// The number of selected bytes per window
+	weight uint32
+	// The runs of selected positions
+	blocks []seedBlock
+}
+
+// A run of consecutive selected positions in a spaced seed.
+type seedBlock struct {
+	// The start of the block within the window
+	offset uint32
+	// The number of positions in the block
+	length uint32
+	// The number of selected positions after the block
+	shift int
+}
+
+// Parses a spaced seed pattern made of '1' (selected) and '0' (ignored)
+// positions.
+func ParseSpacedSeed(pattern string) (*SpacedSeed, error) {
+	n := len(pattern)
+	if n == 0 || pattern[0] != '1' || pattern[n-1] != '1' {
+		return nil, ErrIllegalSeed
+	}
+
+	s := &SpacedSeed{pattern: pattern, span: uint32(n)}
+	for i := 0; i < n; i++ {
+		switch pattern[i] {
+		case '1':
+			if i == 0 || pattern[i-1] == '0' {
+				s.blocks = append(s.blocks, seedBlock{offset: uint32(i)})
+			}
+			s.blocks[len(s.blocks)-1].length++
+			s.weight++
+		case '0':
+		default:
+			return nil, ErrIllegalSeed
+		}
+	}
+
+	after := s.weight
+	for i := range s.blocks {
+		after -= s.blocks[i].length
+		s.blocks[i].shift = int(after)
+	}
+
+	return s, nil
+}
+
+// Span returns the number of bytes covered by a window.
+func (s *SpacedSeed) Span() uint32 {
+	return s.span
+}
+
+// Weight returns the number of bytes that contribute to a window's hash.
+func (s *SpacedSeed) Weight() uint32 {
+	return s.weight
+}
+
+// String returns the pattern of the seed.
+func (s *SpacedSeed) String() string {
+	return s.pattern
+}
+
+// Hash hashes the first Span bytes of the buffer in one shot.
+func (s *SpacedSeed) Hash(buf []byte) (uint64, error) {
+	if s.span > uint32(len(buf)) {
+		return 0, ErrWindowTooLong
+	}
+
+	var h uint64
+	for _, b := range s.blocks {
+		h ^= bits.RotateLeft64(hashBuf(buf[b.offset:b.offset+b.length]), b.shift)
+	}
+
+	return h, nil
+}
+
+// Rolls every block hash of the window at pos one byte forward.
+func (s *SpacedSeed) roll(buf []byte, pos uint32, blockHashes []uint64) {
+	for i, b := range s.blocks {
+		out := buf[pos+b.offset]
+		in := buf[pos+b.offset+b.length]
+
+		blockHashes[i] = bits.RotateLeft64(blockHashes[i], 1) ^
+			bits.RotateLeft64(table[out], int(b.length)) ^
+			table[in]
+	}
+}
+
+// Combines the block hashes into the window hash.
+func (s *SpacedSeed) combine(blockHashes []uint64) uint64 {
+	var h uint64
+	for i, b := range s.blocks {
+		h ^= bits.RotateLeft64(blockHashes[i], b.shift)
+	}
+	return h
+}
+
+// Hashes every block of the window at pos from scratch.
+func (s *SpacedSeed) initBlocks(buf []byte, pos uint32, blockHashes []uint64) {
+	for i, b := range s.blocks {
+		blockHashes[i] = hashBuf(buf[pos+b.offset : pos+b.offset+b.length])
+	}
+}
+
+// Implements RollingHash over a spaced seed. Like Hasher, the buffer is
+// fixed at construction and the hasher is not a streaming hash.
+type SpacedHasher struct {
+	// The inner immutable buffer to hash over
+	buf []byte
+	// The seed selecting the window positions
+	seed *SpacedSeed
+	// The current window start position
+	position uint32
+	// The rolling hash of every block of the current window
+	blockHashes []uint64
+	// The current pre-computed hash
+	hash uint64
+}
+
+// Creates a new spaced-seed rolling hasher over the given buffer with the
+// window starting from 0 index.
+func NewSpaced(buf []byte, seed *SpacedSeed) (RollingHash, error) {
+	if seed.span > uint32(len(buf)) {
+		return nil, ErrWindowTooLong
+	}
+
+	h := &SpacedHasher{
+		buf:         buf,
+		seed:        seed,
+		blockHashes: make([]uint64, len(seed.blocks)),
+	}
+	h.Reset()
+
+	return h, nil
+}
+
+// Rolls the hasing window by the given step. Changes the window start position.
+func (h *SpacedHasher) Roll(step uint32) (uint64, error) {
+	if h.position+step+h.seed.span > uint32(len(h.buf)) {
+		return 0, ErrIllegalRoll
+	}
+
+	for i := uint32(0); i < step; i++ {
+		h.seed.roll(h.buf, h.position, h.blockHashes)
+		h.position++
+	}
+	h.hash = h.seed.combine(h.blockHashes)
+
+	return h.hash, nil
+}
+
+// BulkRoll implements RollingHash.
+func (h *SpacedHasher) BulkRoll(stride uint32) ([]uint64, error) {
+	hashes, err := BulkRollSeeds(h.buf[h.position:], []*SpacedSeed{h.seed}, stride)
+	if err != nil {
+		return nil, err
+	}
+
+	return hashes[0], nil
+}
+
+// BulkRollMatch implements MatchRoller.
+func (h *SpacedHasher) BulkRollMatch(mask, target uint64, stride uint32) ([]uint32, []uint64, error) {
+	var positions []uint32
+	var hashes []uint64
+	err := h.BulkRollFunc(stride, func(pos uint32, hash uint64) bool {
+		if hash&mask == target {
+			positions = append(positions, pos)
+			hashes = append(hashes, hash)
+		}
+		return true
+	})
+
+	return positions, hashes, err
+}
+
+// BulkRollFunc implements FuncRoller.
+func (h *SpacedHasher) BulkRollFunc(stride uint32, fn func(pos uint32, h uint64) bool) error {
+	if stride == 0 {
+		return ErrIllegalStride
+	}
+
+	n := uint32(len(h.buf))
+	blockHashes := append([]uint64(nil), h.blockHashes...)
+
+	for pos := h.position; pos+h.seed.span <= n; pos++ {
+		if (pos-h.position)%stride == 0 && !fn(pos, h.seed.combine(blockHashes)) {
+			return nil
+		}
+		if pos+h.seed.span < n {
+			h.seed.roll(h.buf, pos, blockHashes)
+		}
+	}
+
+	return nil
+}
+
+// BulkRollContext implements FuncRoller.
+func (h *SpacedHasher) BulkRollContext(ctx context.Context, stride uint32, fn func(pos uint32, h uint64) bool) error {
+	return bulkRollContext(ctx, stride, fn, h.BulkRollFunc)
+}
+
+// Get the hash value of the current state of the hasher. Does not change the
+// state in any way.
+func (h *SpacedHasher) Sum64() uint64 {
+	return h.hash
+}
+
+// Sum appends the current hash to b and returns the resulting slice.
+// It does not change the underlying hash state.
+func (h *SpacedHasher) Sum(b []byte) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], h.Sum64())
+	return append(b, buf[:]...)
+}
+
+// Reset the position of this hasher.
+func (h *SpacedHasher) Reset() {
+	h.position = 0
+	h.seed.initBlocks(h.buf, 0, h.blockHashes)
+	h.hash = h.seed.combine(h.blockHashes)
+}
+
+// Size returns the number of bytes Sum will return.
+func (h *SpacedHasher) Size() int {
+	return hashSizeBytes
+}
+
+// Not implemented and not applicable for this hash. The bytes are passed
+// only with NewSpaced and never updated.
+func (h *SpacedHasher) Write(p []byte) (int, error) {
+	return 0, ErrNotWritable
+}
+
+// In buzhash context, a block size doesn't have any impact
+func (h *SpacedHasher) BlockSize() int {
+	return 1
+}
+
+// Get the current position in the input
+func (h *SpacedHasher) Position() uint32 {
+	return h.position
+}
+
+// Clone implements Cloner. The seed and buffer are shared.
+func (h *SpacedHasher) Clone() RollingHash {
+	c := *h
+	c.blockHashes = append([]uint64(nil), h.blockHashes...)
+	return &c
+}
+
+// Evaluates several spaced seeds over the buffer in a single pass and returns
+// the window hashes of every seed at the given stride, indexed like seeds.
+// Seeds with a longer span than the buffer yield no hashes.
+func BulkRollSeeds(buf []byte, seeds []*SpacedSeed, stride uint32) ([][]uint64, error) {
+	if stride == 0 {
+		return nil, ErrIllegalStride
+	}
+
+	n := uint32(len(buf))
+	out := make([][]uint64, len(seeds))
+	state := make([][]uint64, len(seeds))
+	maxWindows := uint32(0)
+
+	for i, s := range seeds {
+		if s.span > n {
+			continue
+		}
+
+		windows := n - s.span + 1
+		maxWindows = max(maxWindows, windows)
+		out[i] = make([]uint64, 0, (windows-1)/stride+1)
+		state[i] = make([]uint64, len(s.blocks))
+		s.initBlocks(buf, 0, state[i])
+	}
+
+	for pos := uint32(0); pos < maxWindows; pos++ {
+		for i, s := range seeds {
+			if state[i] == nil || pos+s.span > n {
+				continue
+			}
+
+			if pos%stride == 0 {
+				out[i] = append(out[i], s.combine(state[i]))
+			}
+			if pos+s.span < n {
+				s.roll(buf, pos, state[i])
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// Table returns the table the hasher hashes with, always TableV1.
+func (h *SpacedHasher) Table() *Table {
+	return tableV1
+}
@@ -0,0 +1,205 @@
+package hasher
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Hashes the bytes selected by the pattern, the definition of a spaced hash.
+func extracted(pattern string, window []byte) uint64 {
+	var sel []byte
+	for i := range pattern {
+		if pattern[i] == '1' {
+			sel = append(sel, window[i])
+		}
+	}
+	return Hash(sel)
+}
+
+func TestParseSpacedSeed(t *testing.T) {
+	s, err := ParseSpacedSeed("1101101")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(7), s.Span())
+	assert.Equal(t, uint32(5), s.Weight())
+	assert.Equal(t, "1101101", s.String())
+	assert.Len(t, s.blocks, 3)
+
+	for _, bad := range []string{"", "0", "011", "110", "1x1", "1 1"} {
+		_, err := ParseSpacedSeed(bad)
+		assert.ErrorIs(t, err, ErrIllegalSeed, "pattern %q", bad)
+	}
+}
+
+func TestSpacedRollMatchesExtracted(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	data := make([]byte, 200)
+	rng.Read(data)
+
+	for _, pattern := range []string{"1", "111", "1101101", "10001", "1111011110111"} {
+		seed, err := ParseSpacedSeed(pattern)
+		assert.NoError(t, err)
+
+		h, err := NewSpaced(data, seed)
+		assert.NoError(t, err)
+		assert.Equal(t, extracted(pattern, data), h.Sum64())
+
+		for i := 1; i+len(pattern) <= len(data); i++ {
+			got, err := h.Roll(1)
+			assert.NoError(t, err)
+			assert.Equal(t, extracted(pattern, data[i:]), got, "pattern %s offset %d", pattern, i)
+
+			one, err := seed.Hash(data[i:])
+			assert.NoError(t, err)
+			assert.Equal(t, got, one)
+		}
+
+		_, err = h.Roll(1)
+		assert.ErrorIs(t, err, ErrIllegalRoll)
+	}
+}
+
+func TestSpacedContiguousEqualsHasher(t *testing.T) {
+	data := []byte("abcdefghijklmnop")
+	seed, _ := ParseSpacedSeed("1111")
+
+	spaced, err := NewSpaced(data, seed)
+	assert.NoError(t, err)
+	plain, err := New(data, 4)
+	assert.NoError(t, err)
+
+	want, _ := plain.BulkRoll(1)
+	got, err := spaced.BulkRoll(1)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestSpacedBulkRoll(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	seed, _ := ParseSpacedSeed("11011")
+
+	h, err := NewSpaced(data, seed)
+	assert.NoError(t, err)
+	_, err = h.Roll(3)
+	assert.NoError(t, err)
+	before := h.Sum64()
+
+	hashes, err := h.BulkRoll(2)
+	assert.NoError(t, err)
+
+	var expected []uint64
+	for i := 3; i+5 <= len(data); i += 2 {
+		expected = append(expected, extracted("11011", data[i:]))
+	}
+	assert.Equal(t, expected, hashes)
+	assert.Equal(t, before, h.Sum64(), "BulkRoll should not mutate internal state")
+	assert.Equal(t, uint32(3), h.Position())
+
+	_, err = h.BulkRoll(0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+
+	h.Reset()
+	assert.Equal(t, extracted("11011", data), h.Sum64())
+}
+
+func TestBulkRollSeeds(t *testing.T) {
+	data := []byte("abracadabra, abracadabra")
+	// The last seed spans more than the input and yields no hashes.
+	patterns := []string{"101", "1100111", "1", "1000000000000000000000000000001"}
+
+	var seeds []*SpacedSeed
+	for _, p := range patterns {
+		s, err := ParseSpacedSeed(p)
+		assert.NoError(t, err)
+		seeds = append(seeds, s)
+	}
+
+	all, err := BulkRollSeeds(data, seeds, 3)
+	assert.NoError(t, err)
+	assert.Len(t, all, len(seeds))
+
+	for i, p := range patterns {
+		var expected []uint64
+		for pos := 0; pos+len(p) <= len(data); pos += 3 {
+			expected = append(expected, extracted(p, data[pos:]))
+		}
+		assert.Equal(t, expected, all[i], "pattern %s", p)
+	}
+
+	_, err = BulkRollSeeds(data, seeds, 0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+}
+
+func TestSpacedErrors(t *testing.T) {
+	seed, _ := ParseSpacedSeed("10101")
+
+	_, err := NewSpaced([]byte("abcd"), seed)
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+
+	_, err = seed.Hash([]byte("abc"))
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+
+	h, _ := NewSpaced([]byte("abcde"), seed)
+	n, err := h.Write([]byte("x"))
+	assert.ErrorIs(t, err, ErrNotWritable)
+	assert.Zero(t, n)
+	assert.Equal(t, 8, h.Size())
+	assert.Equal(t, 1, h.BlockSize())
+	assert.Len(t, h.Sum(nil), 8)
+}
+
+func TestSpacedBulkRollMatch(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	seed, _ := ParseSpacedSeed("1011")
+
+	h, err := NewSpaced(data, seed)
+	assert.NoError(t, err)
+	_, err = h.Roll(2)
+	assert.NoError(t, err)
+
+	positions, hashes, err := BulkRollMatch(h, 0x3, 0x1, 2)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, positions)
+	for i, pos := range positions {
+		assert.Equal(t, extracted("1011", data[pos:]), hashes[i])
+		assert.Equal(t, uint64(0x1), hashes[i]&0x3)
+		assert.Zero(t, (pos-2)%2)
+	}
+
+	_, _, err = BulkRollMatch(h, 0, 0, 0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+}
+
+func TestSpacedBulkRollFunc(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	seed, _ := ParseSpacedSeed("11001")
+
+	h, err := NewSpaced(data, seed)
+	assert.NoError(t, err)
+	_, err = h.Roll(1)
+	assert.NoError(t, err)
+
+	want, _ := h.BulkRoll(3)
+	var got []uint64
+	err = BulkRollContext(context.Background(), h, 3, func(pos uint32, hash uint64) bool {
+		assert.Equal(t, extracted("11001", data[pos:]), hash)
+		got = append(got, hash)
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	calls := 0
+	err = BulkRollFunc(h, 1, func(uint32, uint64) bool {
+		calls++
+		return false
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, uint32(1), h.Position())
+
+	err = BulkRollFunc(h, 0, func(uint32, uint64) bool { return true })
+	assert.ErrorIs(t, err, ErrIllegalStride)
+}
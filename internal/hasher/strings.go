@@ -0,0 +1,20 @@
+package hasher
+
+import "unsafe"
+
+// Views the bytes of s without copying. The hashers never write to a buffer
+// they did not allocate, so the immutability of s is preserved.
+func stringBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
+
+// HashString is Hash over the bytes of s without converting it to a []byte.
+func HashString(s string) uint64 {
+	return hashBuf(stringBytes(s))
+}
+
+// NewString is New over the bytes of s without copying them. Append still
+// copies the input into a buffer owned by the hasher.
+func NewString(s string, windowSize uint32) (RollingHash, error) {
+	return New(stringBytes(s), windowSize)
+}
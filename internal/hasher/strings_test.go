@@ -0,0 +1,82 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var phrase = strings.Repeat("the quick brown fox jumps over the lazy dog ", 20)
+
+func TestHashStringMatchesHash(t *testing.T) {
+	for _, s := range []string{"", "a", "hello", phrase} {
+		assert.Equal(t, Hash([]byte(s)), HashString(s))
+	}
+}
+
+func TestNewStringMatchesNew(t *testing.T) {
+	for _, windowSize := range []uint32{0, 1, 16, 64, 100} {
+		h, err := New([]byte(phrase), windowSize)
+		assert.NoError(t, err)
+		s, err := NewString(phrase, windowSize)
+		assert.NoError(t, err)
+		assert.Equal(t, h.Sum64(), s.Sum64())
+
+		want, err := h.BulkRoll(1)
+		assert.NoError(t, err)
+		got, err := s.BulkRoll(1)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := NewString("ab", 3)
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+
+	h, err := NewString("", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(nil), h.Sum64())
+}
+
+func TestStringAppendDoesNotWriteToString(t *testing.T) {
+	s := strings.Repeat("x", 32)
+	h, err := NewString(s, 4)
+	assert.NoError(t, err)
+	hh := h.(*Hasher)
+
+	_, err = hh.Roll(20)
+	assert.NoError(t, err)
+	hh.Append([]byte("yyyy"))
+	_, err = hh.Roll(12)
+	assert.NoError(t, err)
+	assert.Equal(t, HashString("yyyy"), hh.Sum64())
+	assert.Equal(t, strings.Repeat("x", 32), s)
+}
+
+func TestStringAllocs(t *testing.T) {
+	var sink uint64
+	allocs := testing.AllocsPerRun(100, func() {
+		sink ^= HashString(phrase)
+	})
+	assert.Zero(t, allocs)
+
+	// Only the hasher itself is allocated, the string is never copied.
+	allocs = testing.AllocsPerRun(100, func() {
+		h, _ := NewString(phrase, 64)
+		sink ^= h.Sum64()
+	})
+	assert.Equal(t, float64(1), allocs)
+	_ = sink
+}
+
+func TestRollAllocs(t *testing.T) {
+	h, err := NewString(phrase, 64)
+	assert.NoError(t, err)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := h.Roll(1); err != nil {
+			h.Reset()
+		}
+	})
+	assert.Zero(t, allocs)
+}
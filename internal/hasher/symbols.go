@@ -0,0 +1,223 @@
+package hasher
+
+import (
+	"context"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Symbol is the set of integer types a SymbolHasher can roll over, e.g.
+// token IDs or instruction opcodes.
+type Symbol interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// SeededMapper maps symbols to 64-bit values with a seeded splitmix64
+// finalizer, suitable for symbol spaces of any size.
+func SeededMapper[T Symbol](seed uint64) func(T) uint64 {
+	return func(s T) uint64 {
+		return mix64(seed ^ uint64(s))
+	}
+}
+
+// TableMapper maps every symbol to its entry in the table, e.g. one filled
+// with random values. Rolling over a symbol outside of the table panics.
+func TableMapper[T Symbol](table []uint64) func(T) uint64 {
+	return func(s T) uint64 {
+		return table[s]
+	}
+}
+
+// The splitmix64 finalizer.
+func mix64(z uint64) uint64 {
+	z += 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Implements RollingHash over a slice of integer symbols with the same
+// rotation scheme as Hasher. The window size and all positions count
+// symbols. Symbols are mapped to 64-bit values as they are rolled over, so
+// no copy of the input is made.
+type SymbolHasher[T Symbol] struct {
+	// The inner immutable symbols to hash over
+	syms []T
+	// Maps a symbol to the value mixed into the hash
+	mapper func(T) uint64
+	// The window size in symbols
+	windowSize uint32
+	// The current window start position
+	position uint32
+	// The current pre-computed hash
+	hash uint64
+}
+
+// Creates a new rolling hasher over the symbols with the window starting
+// from 0 index. A nil mapper defaults to SeededMapper with seed 0.
+func NewSymbols[T Symbol](syms []T, windowSize uint32, mapper func(T) uint64) (*SymbolHasher[T], error) {
+	if windowSize > uint32(len(syms)) {
+		return nil, ErrWindowTooLong
+	}
+	if mapper == nil {
+		mapper = SeededMapper[T](0)
+	}
+
+	h := &SymbolHasher[T]{
+		syms:       syms,
+		mapper:     mapper,
+		windowSize: windowSize,
+	}
+	h.Reset()
+
+	return h, nil
+}
+
+// HashSymbols hashes the symbols in one shot without rolling. A nil mapper
+// defaults to SeededMapper with seed 0.
+func HashSymbols[T Symbol](syms []T, mapper func(T) uint64) uint64 {
+	if mapper == nil {
+		mapper = SeededMapper[T](0)
+	}
+	return hashSymbols(syms, mapper)
+}
+
+func hashSymbols[T Symbol](syms []T, mapper func(T) uint64) uint64 {
+	var h uint64
+	n := len(syms)
+
+	for i, s := range syms {
+		h ^= bits.RotateLeft64(mapper(s), n-1-i)
+	}
+
+	return h
+}
+
+// Rolls the hasing window by the given step. Changes the window start position.
+func (h *SymbolHasher[T]) Roll(step uint32) (uint64, error) {
+	if h.position+step+h.windowSize > uint32(len(h.syms)) {
+		return 0, ErrIllegalRoll
+	}
+
+	for i := uint32(0); i < step; i++ {
+		h.hash = bits.RotateLeft64(h.hash, 1) ^
+			bits.RotateLeft64(h.mapper(h.syms[h.position]), int(h.windowSize)) ^
+			h.mapper(h.syms[h.position+h.windowSize])
+		h.position++
+	}
+
+	return h.hash, nil
+}
+
+// BulkRoll implements RollingHash.
+func (h *SymbolHasher[T]) BulkRoll(stride uint32) ([]uint64, error) {
+	if stride == 0 {
+		return nil, ErrIllegalStride
+	}
+
+	hashes := make([]uint64, 0, (uint32(len(h.syms))-h.windowSize-h.position)/stride+1)
+	err := h.BulkRollFunc(stride, func(_ uint32, hash uint64) bool {
+		hashes = append(hashes, hash)
+		return true
+	})
+
+	return hashes, err
+}
+
+// BulkRollMatch implements MatchRoller.
+func (h *SymbolHasher[T]) BulkRollMatch(mask, target uint64, stride uint32) ([]uint32, []uint64, error) {
+	var positions []uint32
+	var hashes []uint64
+	err := h.BulkRollFunc(stride, func(pos uint32, hash uint64) bool {
+		if hash&mask == target {
+			positions = append(positions, pos)
+			hashes = append(hashes, hash)
+		}
+		return true
+	})
+
+	return positions, hashes, err
+}
+
+// BulkRollFunc implements FuncRoller.
+func (h *SymbolHasher[T]) BulkRollFunc(stride uint32, fn func(pos uint32, h uint64) bool) error {
+	if stride == 0 {
+		return ErrIllegalStride
+	}
+
+	n := uint32(len(h.syms))
+	pos := h.position
+	hash := h.hash
+
+	for pos+h.windowSize <= n {
+		if !fn(pos, hash) {
+			return nil
+		}
+
+		for i := uint32(0); i < stride; i++ {
+			if pos+h.windowSize >= n {
+				return nil
+			}
+
+			hash = bits.RotateLeft64(hash, 1) ^
+				bits.RotateLeft64(h.mapper(h.syms[pos]), int(h.windowSize)) ^
+				h.mapper(h.syms[pos+h.windowSize])
+			pos++
+		}
+	}
+
+	return nil
+}
+
+// BulkRollContext implements FuncRoller.
+func (h *SymbolHasher[T]) BulkRollContext(ctx context.Context, stride uint32, fn func(pos uint32, h uint64) bool) error {
+	return bulkRollContext(ctx, stride, fn, h.BulkRollFunc)
+}
+
+// Get the hash value of the current state of the hasher. Does not change the
+// state in any way.
+func (h *SymbolHasher[T]) Sum64() uint64 {
+	return h.hash
+}
+
+// Sum appends the current hash to b and returns the resulting slice.
+// It does not change the underlying hash state.
+func (h *SymbolHasher[T]) Sum(b []byte) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], h.Sum64())
+	return append(b, buf[:]...)
+}
+
+// Reset the position of this hasher.
+func (h *SymbolHasher[T]) Reset() {
+	h.position = 0
+	h.hash = hashSymbols(h.syms[:h.windowSize], h.mapper)
+}
+
+// Size returns the number of bytes Sum will return.
+func (h *SymbolHasher[T]) Size() int {
+	return hashSizeBytes
+}
+
+// Not implemented and not applicable for this hash. The symbols are passed
+// only with NewSymbols and never updated.
+func (h *SymbolHasher[T]) Write(p []byte) (int, error) {
+	return 0, ErrNotWritable
+}
+
+// In buzhash context, a block size doesn't have any impact
+func (h *SymbolHasher[T]) BlockSize() int {
+	return 1
+}
+
+// Get the current position in symbols
+func (h *SymbolHasher[T]) Position() uint32 {
+	return h.position
+}
+
+// Clone implements Cloner. The symbols are shared.
+func (h *SymbolHasher[T]) Clone() RollingHash {
+	c := *h
+	return &c
+}
@@ -0,0 +1,129 @@
+package hasher
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func byteTableMapper(b byte) uint64 {
+	return table[b]
+}
+
+func TestSymbolsMatchHasherWithByteTable(t *testing.T) {
+	buf := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(buf)
+
+	for _, windowSize := range []uint32{0, 1, 16, 64, 65, 1000} {
+		h, err := New(buf, windowSize)
+		assert.NoError(t, err)
+		s, err := NewSymbols(buf, windowSize, byteTableMapper)
+		assert.NoError(t, err)
+		assert.Equal(t, h.Sum64(), s.Sum64())
+
+		want, err := h.BulkRoll(3)
+		assert.NoError(t, err)
+		got, err := s.BulkRoll(3)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	assert.Equal(t, Hash(buf), HashSymbols(buf, byteTableMapper))
+}
+
+func TestSymbolsRollMatchesOneShot(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	tokens := make([]uint32, 500)
+	for i := range tokens {
+		tokens[i] = rng.Uint32() % 50000
+	}
+
+	mapper := SeededMapper[uint32](42)
+	h, err := NewSymbols(tokens, 8, mapper)
+	assert.NoError(t, err)
+
+	for pos := uint32(0); ; pos++ {
+		assert.Equal(t, pos, h.Position())
+		assert.Equal(t, HashSymbols(tokens[pos:pos+8], mapper), h.Sum64())
+
+		if _, err := h.Roll(1); err != nil {
+			assert.ErrorIs(t, err, ErrIllegalRoll)
+			break
+		}
+	}
+	assert.Equal(t, uint32(len(tokens)-8), h.Position())
+
+	h.Reset()
+	hash, err := h.Roll(10)
+	assert.NoError(t, err)
+	assert.Equal(t, HashSymbols(tokens[10:18], mapper), hash)
+}
+
+func TestSymbolsBulkRoll(t *testing.T) {
+	syms := []int16{-3, 7, 7, 1200, -32768, 0, 5, 5, 9}
+	h, err := NewSymbols(syms, 3, nil)
+	assert.NoError(t, err)
+
+	hashes, err := h.BulkRoll(2)
+	assert.NoError(t, err)
+	assert.Len(t, hashes, 4)
+	for i, hash := range hashes {
+		assert.Equal(t, HashSymbols(syms[2*i:2*i+3], nil), hash)
+	}
+
+	var positions []uint32
+	err = h.BulkRollFunc(3, func(pos uint32, _ uint64) bool {
+		positions = append(positions, pos)
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{0, 3, 6}, positions)
+
+	all, err := h.BulkRoll(1)
+	assert.NoError(t, err)
+	matched, matchedHashes, err := h.BulkRollMatch(1, 1, 1)
+	assert.NoError(t, err)
+	for i, pos := range matched {
+		assert.Equal(t, all[pos], matchedHashes[i])
+		assert.Equal(t, uint64(1), matchedHashes[i]&1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, h.BulkRollContext(ctx, 1, func(uint32, uint64) bool { return true }), context.Canceled)
+
+	// The bulk methods leave the hasher where it was.
+	assert.Equal(t, uint32(0), h.Position())
+	_, err = h.BulkRoll(0)
+	assert.ErrorIs(t, err, ErrIllegalStride)
+}
+
+func TestSymbolsSeedAndTable(t *testing.T) {
+	syms := []uint16{1, 2, 3, 4}
+	assert.NotEqual(t, HashSymbols(syms, SeededMapper[uint16](1)), HashSymbols(syms, SeededMapper[uint16](2)))
+	assert.Equal(t, HashSymbols(syms, nil), HashSymbols(syms, SeededMapper[uint16](0)))
+
+	tbl := []uint64{0, 11, 22, 33, 44}
+	h, err := NewSymbols(syms, 2, TableMapper[uint16](tbl))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(11<<1^22), h.Sum64())
+	hash, err := h.Roll(2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(33<<1^44), hash)
+}
+
+func TestSymbolsErrors(t *testing.T) {
+	_, err := NewSymbols([]uint64{1, 2}, 3, nil)
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+
+	h, err := NewSymbols([]uint64{1, 2, 3}, 2, nil)
+	assert.NoError(t, err)
+	_, err = h.Roll(2)
+	assert.ErrorIs(t, err, ErrIllegalRoll)
+	n, err := h.Write([]byte{1})
+	assert.Equal(t, 0, n)
+	assert.ErrorIs(t, err, ErrNotWritable)
+	assert.Len(t, h.Sum(nil), 8)
+}
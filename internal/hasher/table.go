@@ -0,0 +1,117 @@
+package hasher
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrUnknownTable = errors.New("unknown table version")
+
+// TableVersion identifies the table of byte values behind a hash. Hashes are
+// only comparable when computed with the same version. A published version
+// never changes, so persisted hashes stay valid; improvements ship as new
+// versions that have to be selected explicitly.
+type TableVersion uint32
+
+const (
+	// The original table, used by Hash, New and every other constructor that
+	// does not take a table.
+	TableV1 TableVersion = 1
+)
+
+// TableSeeded is set in the version of every table derived by Table.Seeded,
+// so a seeded table never reports the version it was derived from, e.g.
+// TableV1|TableSeeded for seeded TableV1 tables. The version alone does not
+// identify a seeded table: persist the seed returned by Table.Seed with it.
+const TableSeeded TableVersion = 1 << 31
+
+// A Table maps every byte to the 64-bit value that is rotated into a hash.
+type Table struct {
+	// The version the table is published as
+	version TableVersion
+	// The value of every byte
+	values *[256]uint64
+	// The published table the values were derived from with seed, nil for
+	// published tables
+	base *Table
+	// The seed the values were derived with
+	seed uint64
+}
+
+var tableV1 = &Table{version: TableV1, values: &table}
+
+// All published tables by version.
+var tables = map[TableVersion]*Table{TableV1: tableV1}
+
+// Tables returns the published table versions in ascending order.
+func Tables() []TableVersion {
+	versions := make([]TableVersion, 0, len(tables))
+	for v := range tables {
+		versions = append(versions, v)
+	}
+	slices.Sort(versions)
+	return versions
+}
+
+// LookupTable returns the table published as the given version.
+func LookupTable(v TableVersion) (*Table, error) {
+	t, ok := tables[v]
+	if !ok {
+		return nil, ErrUnknownTable
+	}
+	return t, nil
+}
+
+// Version returns the version the table is published as, with TableSeeded set
+// for tables derived by Seeded.
+func (t *Table) Version() TableVersion {
+	return t.version
+}
+
+// Seed returns the seed the table was derived with by Seeded, and false for
+// published tables.
+func (t *Table) Seed() (uint64, bool) {
+	return t.seed, t.base != nil
+}
+
+// Seeded derives an independent table from this one by mixing every value
+// with the seed, e.g. to keep hashes of different tenants apart. Seeding a
+// seeded table reseeds the published table it was derived from.
+//
+// The derived table is as stable as its published table, but only the
+// version and the seed together identify it: its Version has TableSeeded
+// set and Seed returns the seed.
+func (t *Table) Seeded(seed uint64) *Table {
+	if t.base != nil {
+		t = t.base
+	}
+
+	values := new([256]uint64)
+	for i, v := range t.values {
+		values[i] = mix64(v ^ seed)
+	}
+	return &Table{version: t.version | TableSeeded, values: values, base: t, seed: seed}
+}
+
+// Hash hashes the given bytes with the table in one shot without rolling.
+func (t *Table) Hash(buf []byte) uint64 {
+	return hashTable(t.values, buf)
+}
+
+// New is like the package level New but hashes with the table.
+func (t *Table) New(buf []byte, windowSize uint32) (RollingHash, error) {
+	if windowSize > uint32(len(buf)) {
+		return nil, ErrWindowTooLong
+	}
+
+	h := &Hasher{
+		buf:        buf,
+		windowSize: windowSize,
+		hash:       hashTable(t.values, buf[:windowSize]),
+	}
+	if t != tableV1 {
+		h.table = t
+	}
+
+	return h, nil
+}
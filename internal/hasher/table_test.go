@@ -0,0 +1,109 @@
+package hasher
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Pins the output of every published table version. These vectors must never
+// change; a new table gets a new version and its own vectors instead.
+var tableGolden = map[TableVersion]struct {
+	// The SHA-256 of the table values in little-endian order
+	digest string
+	hashes map[string]uint64
+	// BulkRoll(7) over fox with a window of 8
+	bulk []uint64
+}{
+	TableV1: {
+		digest: "a92e88ee63bfd1762d05f42fa813dc429e84597d466f6aed0dae0aa0b69c25e5",
+		hashes: map[string]uint64{
+			"":        0,
+			"a":       0xb054905d9a5189a9,
+			"buzhash": 0xdaac3e417c74b0b0,
+			fox:       0x333b6ce3b4860875,
+		},
+		bulk: []uint64{
+			0x994959d95a3b84f9,
+			0xb00bc6b1976f98b8,
+			0xe5a09502fcbc1f2e,
+			0xbf8ca47b37cbeb89,
+			0xb5e0b359b658a2b5,
+			0xaf025ddf7385f09c,
+		},
+	},
+}
+
+const fox = "The quick brown fox jumps over the lazy dog"
+
+func TestTableGoldenVectors(t *testing.T) {
+	assert.Len(t, tableGolden, len(Tables()), "every published table needs golden vectors")
+
+	for _, v := range Tables() {
+		golden := tableGolden[v]
+		tbl, err := LookupTable(v)
+		assert.NoError(t, err)
+		assert.Equal(t, v, tbl.Version())
+
+		var raw []byte
+		for _, value := range tbl.values {
+			raw = binary.LittleEndian.AppendUint64(raw, value)
+		}
+		digest := sha256.Sum256(raw)
+		assert.Equal(t, golden.digest, hex.EncodeToString(digest[:]), "table %d changed", v)
+
+		for in, want := range golden.hashes {
+			assert.Equal(t, want, tbl.Hash([]byte(in)), "table %d input %q", v, in)
+		}
+
+		h, err := tbl.New([]byte(fox), 8)
+		assert.NoError(t, err)
+		assert.Equal(t, tbl, h.(*Hasher).Table())
+		for _, name := range Backends() {
+			b, err := LookupBackend(name)
+			assert.NoError(t, err)
+			h.(*Hasher).SetBackend(b)
+
+			hashes, err := h.BulkRoll(7)
+			assert.NoError(t, err)
+			assert.Equal(t, golden.bulk, hashes, "table %d backend %s", v, name)
+		}
+	}
+}
+
+func TestDefaultTableIsV1(t *testing.T) {
+	h, err := New([]byte(fox), 8)
+	assert.NoError(t, err)
+	assert.Equal(t, TableV1, h.(*Hasher).Table().Version())
+	assert.Equal(t, TableV1, (&Hasher{}).Table().Version())
+	assert.Equal(t, tableGolden[TableV1].hashes[fox], Hash([]byte(fox)))
+
+	m, err := NewMutable([]byte(fox), 8)
+	assert.NoError(t, err)
+	assert.Equal(t, TableV1, m.Table().Version())
+
+	_, err = LookupTable(0)
+	assert.ErrorIs(t, err, ErrUnknownTable)
+	_, err = tableV1.New([]byte("ab"), 3)
+	assert.ErrorIs(t, err, ErrWindowTooLong)
+}
+
+func TestTableSurvivesResetTo(t *testing.T) {
+	custom := &Table{version: 99, values: &[256]uint64{'a': 1, 'b': 2}}
+	h, err := custom.New([]byte("ab"), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1<<1^2), h.Sum64())
+
+	hh := h.(*Hasher)
+	assert.NoError(t, hh.ResetTo([]byte("ba"), 2))
+	assert.Equal(t, custom, hh.Table())
+	assert.Equal(t, uint64(2<<1^1), hh.Sum64())
+
+	c := hh.Clone()
+	hh.Reset()
+	c.Reset()
+	assert.Equal(t, hh.Sum64(), c.Sum64())
+}
@@ -0,0 +1,220 @@
+// Package minimizer selects minimizers from a stream of rolling hashes: the
+// smallest k-mer hash in every window of w consecutive k-mers.
+//
+// The Iterator keeps a monotone deque of candidates in a ring buffer sized
+// to the window, so every k-mer is pushed and popped at most once and no
+// memory is allocated per window.
+package minimizer
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/satmihir/buzhash"
+)
+
+var ErrIllegalWindow = errors.New("the minimizer window must be at least 1")
+var ErrNoKmers = errors.New("lexicographic tie-breaking needs the k-mer bytes, use FromKmers")
+
+// TieBreak decides which of several equal minimal hashes in a window is
+// selected.
+type TieBreak int
+
+const (
+	// Select the leftmost minimal hash.
+	Leftmost TieBreak = iota
+	// Select the rightmost minimal hash.
+	Rightmost
+	// Robust winnowing: keep the previously selected hash while it is still
+	// a minimum of the window, otherwise select the rightmost one. This
+	// avoids emitting a new minimizer for every window over low-entropy runs.
+	Robust
+	// Select the minimal hash whose k-mer is lexicographically smallest, and
+	// the leftmost of equal k-mers. Bare hashes do not carry the k-mers, so
+	// only FromKmers supports it; FromHashes and FromHasher fail with
+	// ErrNoKmers.
+	Lexicographic
+)
+
+// Minimizer is the selected hash of a window and the position of its k-mer.
+type Minimizer struct {
+	Hash     uint64
+	Position uint32
+}
+
+// Iterator yields the minimizer of each window of w consecutive k-mer
+// hashes.
+type Iterator struct {
+	// Produces the next k-mer hash and its position
+	next func() (Minimizer, bool)
+	// The number of k-mers per window
+	w uint32
+	// The tie-breaking rule
+	tie TieBreak
+	// The bytes and length of the k-mers for Lexicographic
+	kmers []byte
+	k     uint32
+	// The ring buffer backing the monotone deque, with room for one extra
+	// candidate before the evicted front is dropped
+	ring []Minimizer
+	// The index of the deque front in ring
+	head int
+	// The number of candidates in the deque
+	size int
+	// The number of k-mers consumed so far
+	consumed uint32
+	// The previously selected minimizer, for robust winnowing
+	prev Minimizer
+	// Whether prev holds a selection
+	hasPrev bool
+}
+
+// FromHashes iterates over the minimizers of hashes such as the output of
+// BulkRoll(1). Positions are indexes into hashes.
+func FromHashes(hashes []uint64, w uint32, tie TieBreak) (*Iterator, error) {
+	i := 0
+	next := func() (Minimizer, bool) {
+		if i >= len(hashes) {
+			return Minimizer{}, false
+		}
+		m := Minimizer{Hash: hashes[i], Position: uint32(i)}
+		i++
+		return m, true
+	}
+
+	return newIterator(next, w, tie)
+}
+
+// FromHasher iterates over the minimizers of the k-mers visited by rolling h
+// one byte at a time from its current position. Positions are the ones
+// reported by h.Position(). The iterator advances h.
+func FromHasher(h buzhash.RollingHash, w uint32, tie TieBreak) (*Iterator, error) {
+	return newIterator(rollNext(h), w, tie)
+}
+
+// FromKmers is FromHasher for a hasher rolling over buf with a window of k
+// bytes, so that the k-mer at position p is buf[p:p+k]. Knowing the k-mers,
+// it also supports Lexicographic.
+func FromKmers(h buzhash.RollingHash, buf []byte, k, w uint32, tie TieBreak) (*Iterator, error) {
+	if k > uint32(len(buf)) {
+		return nil, buzhash.ErrWindowTooLong
+	}
+
+	it, err := newIterator(rollNext(h), w, Leftmost)
+	if err != nil {
+		return nil, err
+	}
+	it.tie = tie
+	it.kmers = buf
+	it.k = k
+
+	return it, nil
+}
+
+// Yields the hashes of h rolling one byte at a time.
+func rollNext(h buzhash.RollingHash) func() (Minimizer, bool) {
+	started := false
+	done := false
+	next := func() (Minimizer, bool) {
+		if done {
+			return Minimizer{}, false
+		}
+		if started {
+			if _, err := h.Roll(1); err != nil {
+				done = true
+				return Minimizer{}, false
+			}
+		}
+		started = true
+		return Minimizer{Hash: h.Sum64(), Position: h.Position()}, true
+	}
+
+	return next
+}
+
+func newIterator(next func() (Minimizer, bool), w uint32, tie TieBreak) (*Iterator, error) {
+	if w == 0 {
+		return nil, ErrIllegalWindow
+	}
+	if tie == Lexicographic {
+		return nil, ErrNoKmers
+	}
+
+	return &Iterator{
+		next: next,
+		w:    w,
+		tie:  tie,
+		ring: make([]Minimizer, w+1),
+	}, nil
+}
+
+// Next returns the minimizer of the next window. It returns false once fewer
+// than w k-mers remain. The first window is only complete after w k-mers, so
+// inputs shorter than that yield nothing.
+func (it *Iterator) Next() (Minimizer, bool) {
+	for {
+		m, ok := it.next()
+		if !ok {
+			return Minimizer{}, false
+		}
+		it.push(m)
+		it.consumed++
+
+		if it.consumed >= it.w {
+			return it.selectMin(), true
+		}
+	}
+}
+
+// Appends a k-mer to the back of the deque and evicts the front once it
+// falls out of the window.
+func (it *Iterator) push(m Minimizer) {
+	for it.size > 0 {
+		back := it.ring[(it.head+it.size-1)%len(it.ring)]
+		if back.Hash < m.Hash || (back.Hash == m.Hash && it.keepEqual(back, m)) {
+			break
+		}
+		it.size--
+	}
+
+	it.ring[(it.head+it.size)%len(it.ring)] = m
+	it.size++
+
+	// The window covers the last w k-mers, the newest one being m.
+	if front := it.ring[it.head]; m.Position-front.Position >= it.w {
+		it.head = (it.head + 1) % len(it.ring)
+		it.size--
+	}
+}
+
+// Whether an earlier candidate stays in front of a later one with an equal
+// hash. Leftmost keeps earlier candidates and Lexicographic the smaller
+// k-mer, the other modes replace them so the front is the rightmost minimum.
+func (it *Iterator) keepEqual(earlier, later Minimizer) bool {
+	switch it.tie {
+	case Leftmost:
+		return true
+	case Lexicographic:
+		return bytes.Compare(it.kmer(earlier), it.kmer(later)) <= 0
+	default:
+		return false
+	}
+}
+
+func (it *Iterator) kmer(m Minimizer) []byte {
+	return it.kmers[m.Position : m.Position+it.k]
+}
+
+func (it *Iterator) selectMin() Minimizer {
+	front := it.ring[it.head]
+	newest := it.ring[(it.head+it.size-1)%len(it.ring)]
+
+	if it.tie == Robust && it.hasPrev &&
+		it.prev.Hash == front.Hash && newest.Position-it.prev.Position < it.w {
+		return it.prev
+	}
+
+	it.prev = front
+	it.hasPrev = true
+	return front
+}
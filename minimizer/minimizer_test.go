@@ -0,0 +1,182 @@
+package minimizer
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/satmihir/buzhash"
+	"github.com/stretchr/testify/assert"
+)
+
+// Naive O(w) per window reference implementation.
+func bruteForce(hashes []uint64, w int, tie TieBreak) []Minimizer {
+	var out []Minimizer
+	var prev *Minimizer
+
+	for start := 0; start+w <= len(hashes); start++ {
+		best := start
+		for i := start; i < start+w; i++ {
+			if hashes[i] < hashes[best] || (hashes[i] == hashes[best] && tie != Leftmost) {
+				best = i
+			}
+		}
+
+		m := Minimizer{Hash: hashes[best], Position: uint32(best)}
+		if tie == Robust && prev != nil && int(prev.Position) >= start && prev.Hash == m.Hash {
+			m = *prev
+		}
+		out = append(out, m)
+		prev = &m
+	}
+
+	return out
+}
+
+func collect(it *Iterator) []Minimizer {
+	var out []Minimizer
+	for {
+		m, ok := it.Next()
+		if !ok {
+			return out
+		}
+		out = append(out, m)
+	}
+}
+
+func TestMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for _, tie := range []TieBreak{Leftmost, Rightmost, Robust} {
+		for _, w := range []int{1, 2, 5, 16} {
+			hashes := make([]uint64, 300)
+			for i := range hashes {
+				// A tiny value range forces plenty of ties.
+				hashes[i] = uint64(rng.Intn(8))
+			}
+
+			it, err := FromHashes(hashes, uint32(w), tie)
+			assert.NoError(t, err)
+			assert.Equal(t, bruteForce(hashes, w, tie), collect(it), "tie=%d w=%d", tie, w)
+		}
+	}
+}
+
+func TestShortInput(t *testing.T) {
+	it, err := FromHashes([]uint64{3, 1}, 3, Leftmost)
+	assert.NoError(t, err)
+	_, ok := it.Next()
+	assert.False(t, ok)
+
+	_, err = FromHashes(nil, 0, Leftmost)
+	assert.ErrorIs(t, err, ErrIllegalWindow)
+}
+
+func TestRobustWinnowingOnRuns(t *testing.T) {
+	// Over a constant run leftmost moves every window while robust only
+	// moves once the selection leaves the window.
+	hashes := []uint64{5, 5, 5, 5, 5, 5}
+
+	it, _ := FromHashes(hashes, 3, Robust)
+	var positions []uint32
+	for _, m := range collect(it) {
+		positions = append(positions, m.Position)
+	}
+	assert.Equal(t, []uint32{2, 2, 2, 5}, positions)
+
+	it, _ = FromHashes(hashes, 3, Leftmost)
+	for i, m := range collect(it) {
+		assert.Equal(t, uint32(i), m.Position)
+	}
+}
+
+func TestFromHasher(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog, again and again")
+	h, err := buzhash.New(data, 4)
+	assert.NoError(t, err)
+
+	hashes, err := h.BulkRoll(1)
+	assert.NoError(t, err)
+	want, _ := FromHashes(hashes, 5, Robust)
+
+	got, err := FromHasher(h, 5, Robust)
+	assert.NoError(t, err)
+	assert.Equal(t, collect(want), collect(got))
+}
+
+// Naive reference for Lexicographic over the k-mers of buf.
+func bruteForceLexicographic(hashes []uint64, buf []byte, k, w int) []Minimizer {
+	var out []Minimizer
+	for start := 0; start+w <= len(hashes); start++ {
+		best := start
+		for i := start; i < start+w; i++ {
+			if hashes[i] < hashes[best] ||
+				(hashes[i] == hashes[best] && bytes.Compare(buf[i:i+k], buf[best:best+k]) < 0) {
+				best = i
+			}
+		}
+		out = append(out, Minimizer{Hash: hashes[best], Position: uint32(best)})
+	}
+	return out
+}
+
+func TestLexicographic(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	buf := make([]byte, 400)
+	for i := range buf {
+		buf[i] = "ACGT"[rng.Intn(4)]
+	}
+
+	for _, k := range []uint32{3, 6} {
+		for _, w := range []uint32{1, 4, 11} {
+			// Four output bits make most equal hashes belong to different
+			// k-mers.
+			h, err := buzhash.NewWithOptions(buf, k, buzhash.WithOutputBits(4))
+			assert.NoError(t, err)
+			hashes, err := h.BulkRoll(1)
+			assert.NoError(t, err)
+
+			it, err := FromKmers(h, buf, k, w, Lexicographic)
+			assert.NoError(t, err)
+			got := collect(it)
+			assert.Equal(t, bruteForceLexicographic(hashes, buf, int(k), int(w)), got, "k=%d w=%d", k, w)
+			if w > 1 {
+				assert.NotEqual(t, bruteForce(hashes, int(w), Leftmost), got, "k=%d w=%d", k, w)
+			}
+
+			// The other modes work over k-mers as well.
+			h.Reset()
+			it, err = FromKmers(h, buf, k, w, Robust)
+			assert.NoError(t, err)
+			assert.Equal(t, bruteForce(hashes, int(w), Robust), collect(it))
+		}
+	}
+}
+
+func TestLexicographicNeedsKmers(t *testing.T) {
+	_, err := FromHashes([]uint64{1, 2}, 2, Lexicographic)
+	assert.ErrorIs(t, err, ErrNoKmers)
+
+	h, err := buzhash.New([]byte("abcdef"), 2)
+	assert.NoError(t, err)
+	_, err = FromHasher(h, 2, Lexicographic)
+	assert.ErrorIs(t, err, ErrNoKmers)
+
+	_, err = FromKmers(h, []byte("a"), 2, 2, Lexicographic)
+	assert.ErrorIs(t, err, buzhash.ErrWindowTooLong)
+	_, err = FromKmers(h, []byte("abcdef"), 2, 0, Lexicographic)
+	assert.ErrorIs(t, err, ErrIllegalWindow)
+}
+
+func TestNextDoesNotAllocate(t *testing.T) {
+	hashes := make([]uint64, 10000)
+	for i := range hashes {
+		hashes[i] = uint64(i * 7919 % 1000)
+	}
+	it, _ := FromHashes(hashes, 8, Leftmost)
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		it.Next()
+	})
+	assert.Zero(t, allocs)
+}
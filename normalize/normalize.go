@@ -0,0 +1,247 @@
+// Package normalize hashes windows of normalized text, e.g. case folded with
+// punctuation stripped and whitespace runs collapsed, while reporting every
+// window's byte offsets in the original text.
+//
+// The text is decoded into runes and every rune is passed through a pipeline
+// of rules. Each byte of the normalized text remembers the original rune it
+// came from, so windows over the normalized text map back to the smallest
+// range of original bytes that produced them. Invalid UTF-8 is treated as
+// U+FFFD, like ranging over a string.
+package normalize
+
+import (
+	"errors"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/satmihir/buzhash"
+)
+
+var ErrIllegalRange = errors.New("the range is outside of the normalized text")
+
+// Rule appends the normalized form of r to dst and returns the extended
+// slice. Appending nothing drops the rune. last is the previous rune the rule
+// produced, or -1 before it produced any, which lets rules collapse runs.
+type Rule func(dst []rune, last, r rune) []rune
+
+// FoldCase maps every rune to one representative of the runes that
+// unicode.SimpleFold considers equal to it, e.g. K, k and the Kelvin sign,
+// or Σ, σ and ς. The representative is the lower case of the smallest rune
+// of that orbit, so ASCII folds to lower case. Folding is rune to rune:
+// ß and ẞ fold together, but not to "ss".
+func FoldCase(dst []rune, _, r rune) []rune {
+	least := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		least = min(least, f)
+	}
+	return append(dst, unicode.ToLower(least))
+}
+
+// StripPunct drops punctuation and symbols.
+func StripPunct(dst []rune, _, r rune) []rune {
+	if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+		return dst
+	}
+	return append(dst, r)
+}
+
+// CollapseSpace replaces every run of white space with a single space.
+func CollapseSpace(dst []rune, last, r rune) []rune {
+	if !unicode.IsSpace(r) {
+		return append(dst, r)
+	}
+	if last == ' ' {
+		return dst
+	}
+	return append(dst, ' ')
+}
+
+// Text is normalized text that remembers where every byte came from.
+type Text struct {
+	// The normalized text
+	norm []byte
+	// The original start offset of the rune each normalized byte came from
+	starts []uint32
+	// The original end offset of the rune each normalized byte came from
+	ends []uint32
+	// The length of the original text
+	srcLen uint32
+}
+
+// Apply normalizes the text with the rules in order. Without rules the text
+// is only decoded and re-encoded, which replaces invalid UTF-8.
+func Apply(src []byte, rules ...Rule) *Text {
+	t := &Text{
+		norm:   make([]byte, 0, len(src)),
+		starts: make([]uint32, 0, len(src)),
+		ends:   make([]uint32, 0, len(src)),
+		srcLen: uint32(len(src)),
+	}
+
+	last := make([]rune, len(rules))
+	for i := range last {
+		last[i] = -1
+	}
+
+	var cur, next []rune
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRune(src[i:])
+
+		cur = append(cur[:0], r)
+		for j, rule := range rules {
+			next = next[:0]
+			for _, x := range cur {
+				n := len(next)
+				next = rule(next, last[j], x)
+				if len(next) > n {
+					last[j] = next[len(next)-1]
+				}
+			}
+			cur, next = next, cur
+		}
+
+		for _, x := range cur {
+			n := len(t.norm)
+			t.norm = utf8.AppendRune(t.norm, x)
+			for range len(t.norm) - n {
+				t.starts = append(t.starts, uint32(i))
+				t.ends = append(t.ends, uint32(i+size))
+			}
+		}
+		i += size
+	}
+
+	return t
+}
+
+// Bytes returns the normalized text.
+func (t *Text) Bytes() []byte {
+	return t.norm
+}
+
+// Original maps the range [start, end) of the normalized text to the range
+// of original bytes it came from. An empty range maps to the original offset
+// of its position.
+func (t *Text) Original(start, end uint32) (uint32, uint32, error) {
+	if start > end || end > uint32(len(t.norm)) {
+		return 0, 0, ErrIllegalRange
+	}
+	if start == end {
+		if start == uint32(len(t.norm)) {
+			return t.srcLen, t.srcLen, nil
+		}
+		return t.starts[start], t.starts[start], nil
+	}
+	return t.starts[start], t.ends[end-1], nil
+}
+
+// Window is a window of the normalized text with its hash and the byte
+// offsets of the original text it came from.
+type Window struct {
+	// The offset of the window in the normalized text
+	Position uint32
+	// The original offset of the first byte
+	Start uint32
+	// The original offset just past the last byte
+	End  uint32
+	Hash uint64
+}
+
+// Hasher rolls a buzhash window over normalized text. Hashes equal
+// buzhash.Hash over the normalized bytes of the window, so a normalized
+// phrase can be looked up directly.
+type Hasher struct {
+	text *Text
+	h    buzhash.RollingHash
+	// The window size in normalized bytes
+	windowSize uint32
+}
+
+// Creates a new hasher over the text normalized with the rules, with the
+// window starting at the beginning of the normalized text.
+func New(src []byte, windowSize uint32, rules ...Rule) (*Hasher, error) {
+	text := Apply(src, rules...)
+	h, err := buzhash.New(text.norm, windowSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Hasher{
+		text:       text,
+		h:          h,
+		windowSize: windowSize,
+	}, nil
+}
+
+// Text returns the normalized text.
+func (h *Hasher) Text() *Text {
+	return h.text
+}
+
+// Roll rolls the window over the normalized text by the given step.
+func (h *Hasher) Roll(step uint32) (uint64, error) {
+	return h.h.Roll(step)
+}
+
+// Sum64 returns the hash of the current window.
+func (h *Hasher) Sum64() uint64 {
+	return h.h.Sum64()
+}
+
+// Reset moves the window back to the beginning.
+func (h *Hasher) Reset() {
+	h.h.Reset()
+}
+
+// Position returns the offset of the current window in the normalized text.
+func (h *Hasher) Position() uint32 {
+	return h.h.Position()
+}
+
+// Offsets returns the original byte offsets [start, end) of the current
+// window.
+func (h *Hasher) Offsets() (uint32, uint32) {
+	pos := h.h.Position()
+	start, end, _ := h.text.Original(pos, pos+h.windowSize)
+	return start, end
+}
+
+// BulkRoll reports every window from the current position on, moving by
+// stride bytes of normalized text, without changing the hasher state.
+func (h *Hasher) BulkRoll(stride uint32) ([]Window, error) {
+	var windows []Window
+	err := buzhash.BulkRollFunc(h.h, stride, func(pos uint32, hash uint64) bool {
+		start, end, _ := h.text.Original(pos, pos+h.windowSize)
+		windows = append(windows, Window{
+			Position: pos,
+			Start:    start,
+			End:      end,
+			Hash:     hash,
+		})
+		return true
+	})
+
+	return windows, err
+}
+
+// BulkRollMatch reports the windows whose hash matches target under mask,
+// e.g. the windows of a phrase hashed with buzhash.Hash.
+func (h *Hasher) BulkRollMatch(mask, target uint64, stride uint32) ([]Window, error) {
+	var windows []Window
+	err := buzhash.BulkRollFunc(h.h, stride, func(pos uint32, hash uint64) bool {
+		if hash&mask != target {
+			return true
+		}
+
+		start, end, _ := h.text.Original(pos, pos+h.windowSize)
+		windows = append(windows, Window{
+			Position: pos,
+			Start:    start,
+			End:      end,
+			Hash:     hash,
+		})
+		return true
+	})
+
+	return windows, err
+}
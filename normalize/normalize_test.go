@@ -0,0 +1,145 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/satmihir/buzhash"
+	"github.com/stretchr/testify/assert"
+)
+
+var rules = []Rule{FoldCase, StripPunct, CollapseSpace}
+
+func TestApply(t *testing.T) {
+	text := Apply([]byte("Hello,   World!\n\tÜber  ALLES."), rules...)
+	assert.Equal(t, "hello world über alles", string(text.Bytes()))
+
+	// "world" maps back to "World".
+	start, end, err := text.Original(6, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(9), start)
+	assert.Equal(t, uint32(14), end)
+
+	// The collapsed space maps back to the rune that started the run.
+	start, end, err = text.Original(5, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello,   World"[start:end], " ")
+
+	// "ü" is two bytes in both texts.
+	start, end, err = text.Original(12, 14)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ü", "Hello,   World!\n\tÜber  ALLES."[start:end])
+
+	start, end, err = text.Original(3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), start)
+	assert.Equal(t, uint32(3), end)
+
+	start, end, err = text.Original(uint32(len(text.Bytes())), uint32(len(text.Bytes())))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(30), start)
+	assert.Equal(t, uint32(30), end)
+
+	_, _, err = text.Original(4, 3)
+	assert.ErrorIs(t, err, ErrIllegalRange)
+	_, _, err = text.Original(0, 100)
+	assert.ErrorIs(t, err, ErrIllegalRange)
+}
+
+func TestFoldCase(t *testing.T) {
+	fold := func(s string) string {
+		return string(Apply([]byte(s), FoldCase).Bytes())
+	}
+
+	assert.Equal(t, "hello world", fold("Hello WORLD"))
+	assert.Equal(t, fold("k"), fold("\u212a"))
+	assert.Equal(t, fold("ß"), fold("ẞ"))
+	// Lowering alone leaves final sigma and the theta symbol apart.
+	assert.Equal(t, fold("σ"), fold("ς"))
+	assert.Equal(t, fold("ΣΟΦΟΣ"), fold("σοφος"))
+	assert.Equal(t, fold("θ"), fold("ϑ"))
+	assert.NotEqual(t, fold("ß"), fold("ss"))
+}
+
+func TestApplyWithoutRules(t *testing.T) {
+	text := Apply([]byte("ab\xffc"))
+	assert.Equal(t, "ab�c", string(text.Bytes()))
+
+	start, end, err := text.Original(2, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), start)
+	assert.Equal(t, uint32(3), end)
+}
+
+func TestCustomRule(t *testing.T) {
+	// Expands ß into ss, so normalized bytes outnumber the original ones.
+	expand := func(dst []rune, _, r rune) []rune {
+		if r == 'ß' {
+			return append(dst, 's', 's')
+		}
+		return append(dst, r)
+	}
+
+	text := Apply([]byte("Straße"), FoldCase, expand)
+	assert.Equal(t, "strasse", string(text.Bytes()))
+
+	start, end, err := text.Original(4, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "ß", "Straße"[start:end])
+}
+
+func TestHasherFindsPhrase(t *testing.T) {
+	src := []byte("The QUICK brown fox -- jumps over the quick,\n  brown dog.")
+	phrase := []byte("quick brown")
+
+	h, err := New(src, uint32(len(phrase)), rules...)
+	assert.NoError(t, err)
+
+	windows, err := h.BulkRollMatch(^uint64(0), buzhash.Hash(phrase), 1)
+	assert.NoError(t, err)
+	assert.Len(t, windows, 2)
+	assert.Equal(t, "QUICK brown", string(src[windows[0].Start:windows[0].End]))
+	assert.Equal(t, "quick,\n  brown", string(src[windows[1].Start:windows[1].End]))
+
+	// The hasher itself has not moved.
+	assert.Equal(t, uint32(0), h.Position())
+	start, end := h.Offsets()
+	assert.Equal(t, "The QUICK b", string(src[start:end]))
+}
+
+func TestHasherRoll(t *testing.T) {
+	src := []byte("A,  b; C d")
+	h, err := New(src, 3, rules...)
+	assert.NoError(t, err)
+
+	norm := h.Text().Bytes()
+	assert.Equal(t, "a b c d", string(norm))
+
+	all, err := h.BulkRoll(1)
+	assert.NoError(t, err)
+	assert.Len(t, all, 5)
+
+	for i, w := range all {
+		assert.Equal(t, uint32(i), h.Position())
+		assert.Equal(t, buzhash.Hash(norm[i:i+3]), h.Sum64())
+		assert.Equal(t, w.Hash, h.Sum64())
+
+		start, end := h.Offsets()
+		assert.Equal(t, w.Start, start)
+		assert.Equal(t, w.End, end)
+
+		if i < len(all)-1 {
+			_, err := h.Roll(1)
+			assert.NoError(t, err)
+		}
+	}
+	assert.Equal(t, "b; C", string(src[all[2].Start:all[2].End]))
+
+	_, err = h.Roll(1)
+	assert.ErrorIs(t, err, buzhash.ErrIllegalRoll)
+
+	h.Reset()
+	assert.Equal(t, uint32(0), h.Position())
+
+	_, err = New(src, 100, rules...)
+	assert.ErrorIs(t, err, buzhash.ErrWindowTooLong)
+}
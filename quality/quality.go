@@ -0,0 +1,283 @@
+// Package quality measures the statistical quality of a hash function over
+// the sliding windows of a corpus and produces structured reports.
+//
+// It covers the properties that matter for rolling hashes: bin uniformity
+// with a chi-square test, per-bit entropy, trailing-zero decay (used by
+// content-defined chunking), avalanche and bit independence, and collisions
+// compared to the birthday bound.
+package quality
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+var ErrIllegalConfig = errors.New("the window size must be positive and fit the corpus")
+
+// HashFunc is the function under analysis.
+type HashFunc func([]byte) uint64
+
+// Config controls an analysis. Zero fields take their defaults.
+type Config struct {
+	// The number of bytes per hashed window, required
+	WindowSize int
+	// The number of bins for the uniformity test, 65536 by default
+	Bins int
+	// The number of windows sampled for the avalanche test, 256 by default
+	AvalancheSamples int
+	// The number of low hash bits compared for collisions, 32 by default
+	CollisionBits int
+}
+
+// Report holds the results of an analysis.
+type Report struct {
+	// The number of windows hashed
+	Windows       int               `json:"windows"`
+	WindowSize    int               `json:"window_size"`
+	Uniformity    Uniformity        `json:"uniformity"`
+	BitEntropy    []float64         `json:"bit_entropy"`
+	MinBitEntropy float64           `json:"min_bit_entropy"`
+	TrailingZeros []TrailingZeroBin `json:"trailing_zeros"`
+	Avalanche     Avalanche         `json:"avalanche"`
+	Collisions    Collisions        `json:"collisions"`
+}
+
+// Uniformity describes how evenly hashes spread over bins (hash mod bins).
+type Uniformity struct {
+	Bins   int     `json:"bins"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	// Pearson's chi-square statistic against a uniform spread
+	ChiSquare float64 `json:"chi_square"`
+	// The probability of a statistic at least this large for an ideal hash
+	PValue float64 `json:"p_value"`
+}
+
+// TrailingZeroBin compares the number of hashes with exactly Zeros trailing
+// zero bits to the ideal geometric decay.
+type TrailingZeroBin struct {
+	Zeros    int     `json:"zeros"`
+	Observed int     `json:"observed"`
+	Expected float64 `json:"expected"`
+}
+
+// Avalanche summarizes how output bits react to single input bit flips.
+type Avalanche struct {
+	// The number of input bit flips tried
+	Trials int `json:"trials"`
+	// The average probability of an output bit flipping, ideally 0.5
+	MeanFlipProbability float64 `json:"mean_flip_probability"`
+	// The largest deviation of any output bit from 0.5
+	MaxBias float64 `json:"max_bias"`
+	// The largest correlation between the flips of two output bits,
+	// ideally close to 0 (bit independence criterion)
+	MaxBitCorrelation float64 `json:"max_bit_correlation"`
+}
+
+// Collisions compares collisions among distinct windows to the birthday
+// bound for hashes truncated to Bits bits.
+type Collisions struct {
+	Bits     int     `json:"bits"`
+	Distinct int     `json:"distinct_inputs"`
+	Observed int     `json:"observed"`
+	Expected float64 `json:"expected"`
+}
+
+// Analyze hashes every window of cfg.WindowSize bytes of the corpus with fn
+// and reports on the results.
+func Analyze(fn HashFunc, corpus []byte, cfg Config) (*Report, error) {
+	if cfg.WindowSize <= 0 || cfg.WindowSize > len(corpus) {
+		return nil, ErrIllegalConfig
+	}
+	if cfg.Bins <= 0 {
+		cfg.Bins = 65536
+	}
+	if cfg.AvalancheSamples <= 0 {
+		cfg.AvalancheSamples = 256
+	}
+	if cfg.CollisionBits <= 0 || cfg.CollisionBits > 64 {
+		cfg.CollisionBits = 32
+	}
+
+	n := len(corpus) - cfg.WindowSize + 1
+	hashes := make([]uint64, n)
+	for i := range hashes {
+		hashes[i] = fn(corpus[i : i+cfg.WindowSize])
+	}
+
+	r := &Report{
+		Windows:       n,
+		WindowSize:    cfg.WindowSize,
+		Uniformity:    uniformity(hashes, cfg.Bins),
+		BitEntropy:    bitEntropy(hashes),
+		TrailingZeros: trailingZeros(hashes),
+		Avalanche:     avalanche(fn, corpus, cfg.WindowSize, cfg.AvalancheSamples),
+		Collisions:    collisions(corpus, hashes, cfg.WindowSize, cfg.CollisionBits),
+	}
+
+	r.MinBitEntropy = 1
+	for _, e := range r.BitEntropy {
+		r.MinBitEntropy = math.Min(r.MinBitEntropy, e)
+	}
+
+	return r, nil
+}
+
+func uniformity(hashes []uint64, bins int) Uniformity {
+	counts := make([]int, bins)
+	for _, h := range hashes {
+		counts[h%uint64(bins)]++
+	}
+
+	mean := float64(len(hashes)) / float64(bins)
+	var variance, chi float64
+	for _, c := range counts {
+		diff := float64(c) - mean
+		variance += diff * diff
+		chi += diff * diff / mean
+	}
+	variance /= float64(bins)
+
+	return Uniformity{
+		Bins:      bins,
+		Mean:      mean,
+		StdDev:    math.Sqrt(variance),
+		ChiSquare: chi,
+		PValue:    chiSquarePValue(chi, bins-1),
+	}
+}
+
+func bitEntropy(hashes []uint64) []float64 {
+	var ones [64]int
+	for _, h := range hashes {
+		for i := 0; i < 64; i++ {
+			ones[i] += int((h >> i) & 1)
+		}
+	}
+
+	entropy := make([]float64, 64)
+	for i := range entropy {
+		p1 := float64(ones[i]) / float64(len(hashes))
+		p0 := 1 - p1
+		if p0 > 0 {
+			entropy[i] -= p0 * math.Log2(p0)
+		}
+		if p1 > 0 {
+			entropy[i] -= p1 * math.Log2(p1)
+		}
+	}
+
+	return entropy
+}
+
+func trailingZeros(hashes []uint64) []TrailingZeroBin {
+	var counts [65]int
+	maxZeros := 0
+	for _, h := range hashes {
+		z := bits.TrailingZeros64(h)
+		counts[z]++
+		maxZeros = max(maxZeros, z)
+	}
+
+	out := make([]TrailingZeroBin, maxZeros+1)
+	for z := range out {
+		// An ideal hash has exactly z trailing zeros with probability
+		// 2^-(z+1), and is zero with probability 2^-64.
+		p := math.Ldexp(1, -(z + 1))
+		if z == 64 {
+			p = math.Ldexp(1, -64)
+		}
+		out[z] = TrailingZeroBin{Zeros: z, Observed: counts[z], Expected: p * float64(len(hashes))}
+	}
+
+	return out
+}
+
+func avalanche(fn HashFunc, corpus []byte, windowSize, samples int) Avalanche {
+	n := len(corpus) - windowSize + 1
+	samples = min(samples, n)
+
+	var flips [64]int
+	var pairs [64][64]int
+	trials := 0
+	window := make([]byte, windowSize)
+
+	for s := 0; s < samples; s++ {
+		// Spread the samples evenly over the corpus.
+		start := s * n / samples
+		copy(window, corpus[start:start+windowSize])
+		base := fn(window)
+
+		for bit := 0; bit < windowSize*8; bit++ {
+			window[bit/8] ^= 1 << (bit % 8)
+			diff := fn(window) ^ base
+			window[bit/8] ^= 1 << (bit % 8)
+			trials++
+
+			for d := diff; d != 0; d &= d - 1 {
+				j := bits.TrailingZeros64(d)
+				flips[j]++
+				for e := d & (d - 1); e != 0; e &= e - 1 {
+					pairs[j][bits.TrailingZeros64(e)]++
+				}
+			}
+		}
+	}
+
+	a := Avalanche{Trials: trials}
+	if trials == 0 {
+		return a
+	}
+
+	var p [64]float64
+	for j := range p {
+		p[j] = float64(flips[j]) / float64(trials)
+		a.MeanFlipProbability += p[j] / 64
+		a.MaxBias = math.Max(a.MaxBias, math.Abs(p[j]-0.5))
+	}
+
+	for j := 0; j < 64; j++ {
+		for k := j + 1; k < 64; k++ {
+			varJK := p[j] * (1 - p[j]) * p[k] * (1 - p[k])
+			if varJK == 0 {
+				continue
+			}
+			cov := float64(pairs[j][k])/float64(trials) - p[j]*p[k]
+			a.MaxBitCorrelation = math.Max(a.MaxBitCorrelation, math.Abs(cov/math.Sqrt(varJK)))
+		}
+	}
+
+	return a
+}
+
+func collisions(corpus []byte, hashes []uint64, windowSize, bitCount int) Collisions {
+	mask := ^uint64(0)
+	if bitCount < 64 {
+		mask = 1<<bitCount - 1
+	}
+
+	seen := make(map[string]struct{}, len(hashes))
+	distinctHashes := make(map[uint64]struct{}, len(hashes))
+	for i, h := range hashes {
+		w := string(corpus[i : i+windowSize])
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		seen[w] = struct{}{}
+		distinctHashes[h&mask] = struct{}{}
+	}
+
+	// The expected number of collisions when throwing n balls into m bins
+	// is n - m*(1 - (1-1/m)^n).
+	n := float64(len(seen))
+	m := math.Ldexp(1, bitCount)
+	expected := n + m*math.Expm1(n*math.Log1p(-1/m))
+
+	return Collisions{
+		Bits:     bitCount,
+		Distinct: len(seen),
+		Observed: len(seen) - len(distinctHashes),
+		Expected: math.Max(expected, 0),
+	}
+}
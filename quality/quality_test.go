@@ -0,0 +1,96 @@
+package quality
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/satmihir/buzhash"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomCorpus(n int) []byte {
+	buf := make([]byte, n)
+	rand.New(rand.NewSource(1)).Read(buf)
+	return buf
+}
+
+func TestAnalyzeBuzhash(t *testing.T) {
+	corpus := randomCorpus(200000)
+	r, err := Analyze(buzhash.Hash, corpus, Config{WindowSize: 6, Bins: 1024})
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(corpus)-5, r.Windows)
+	assert.Greater(t, r.Uniformity.PValue, 0.001)
+	assert.InDelta(t, float64(r.Windows)/1024, r.Uniformity.Mean, 1e-9)
+
+	assert.Len(t, r.BitEntropy, 64)
+	assert.Greater(t, r.MinBitEntropy, 0.999)
+
+	assert.Equal(t, 0, r.TrailingZeros[0].Zeros)
+	assert.InDelta(t, r.TrailingZeros[0].Expected, float64(r.TrailingZeros[0].Observed), 0.02*float64(r.Windows))
+
+	assert.Equal(t, 256*6*8, r.Avalanche.Trials)
+	assert.Greater(t, r.Avalanche.MeanFlipProbability, 0.0)
+
+	assert.Equal(t, 32, r.Collisions.Bits)
+	assert.Less(t, float64(r.Collisions.Observed), 3*r.Collisions.Expected+10)
+}
+
+func TestAnalyzeDetectsBadHash(t *testing.T) {
+	// Only looks at the first byte.
+	firstByte := func(p []byte) uint64 { return uint64(p[0]) }
+
+	r, err := Analyze(firstByte, randomCorpus(50000), Config{WindowSize: 4, Bins: 4096, CollisionBits: 64})
+	assert.NoError(t, err)
+
+	assert.Less(t, r.Uniformity.PValue, 1e-6)
+	assert.Zero(t, r.MinBitEntropy)
+	assert.InDelta(t, 0.5, r.Avalanche.MaxBias, 1e-9)
+	assert.Greater(t, r.Collisions.Observed, 40000)
+	assert.Less(t, r.Collisions.Expected, 1e-6)
+}
+
+func TestAnalyzeConfig(t *testing.T) {
+	_, err := Analyze(buzhash.Hash, []byte("abc"), Config{})
+	assert.ErrorIs(t, err, ErrIllegalConfig)
+	_, err = Analyze(buzhash.Hash, []byte("abc"), Config{WindowSize: 4})
+	assert.ErrorIs(t, err, ErrIllegalConfig)
+
+	r, err := Analyze(buzhash.Hash, []byte("abcdef"), Config{WindowSize: 6})
+	assert.NoError(t, err)
+	assert.Equal(t, 65536, r.Uniformity.Bins)
+	assert.Equal(t, 1, r.Avalanche.Trials/48)
+}
+
+func TestUpperGamma(t *testing.T) {
+	// Q(1, x) = e^-x and a chi-square with 2 degrees of freedom has
+	// survival function e^(-x/2).
+	for _, x := range []float64{0.1, 1, 2.5, 10} {
+		assert.InDelta(t, math.Exp(-x), upperGamma(1, x), 1e-12)
+		assert.InDelta(t, math.Exp(-x/2), chiSquarePValue(x, 2), 1e-12)
+	}
+
+	assert.Equal(t, 1.0, upperGamma(3, 0))
+	// The median of a chi-square is close to its degrees of freedom.
+	assert.InDelta(t, 0.5, chiSquarePValue(65535, 65535), 0.01)
+}
+
+func TestRender(t *testing.T) {
+	r, err := Analyze(buzhash.Hash, randomCorpus(5000), Config{WindowSize: 6})
+	assert.NoError(t, err)
+
+	var text bytes.Buffer
+	assert.NoError(t, r.WriteText(&text))
+	assert.Contains(t, text.String(), "Uniformity (mod 65536)")
+	assert.Contains(t, text.String(), "Bit 63: entropy")
+	assert.Contains(t, text.String(), "birthday bound")
+
+	var js bytes.Buffer
+	assert.NoError(t, r.WriteJSON(&js))
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(js.Bytes(), &decoded))
+	assert.Equal(t, *r, decoded)
+}
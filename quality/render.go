@@ -0,0 +1,49 @@
+package quality
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes a human readable rendering of the report.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Windows: %d of %d bytes\n", r.Windows, r.WindowSize)
+
+	u := r.Uniformity
+	fmt.Fprintf(&b, "\nUniformity (mod %d):\n", u.Bins)
+	fmt.Fprintf(&b, "Mean: %.2f, StdDev: %.2f\n", u.Mean, u.StdDev)
+	fmt.Fprintf(&b, "Chi-square: %.2f, p-value: %.4f\n", u.ChiSquare, u.PValue)
+
+	fmt.Fprintf(&b, "\nBit entropy per bit (min %.4f):\n", r.MinBitEntropy)
+	for i, e := range r.BitEntropy {
+		fmt.Fprintf(&b, "Bit %2d: entropy = %.4f\n", i, e)
+	}
+
+	fmt.Fprintln(&b, "\nTrailing zero distribution:")
+	for _, z := range r.TrailingZeros {
+		fmt.Fprintf(&b, "%2d zeros: %8d (ideal %10.1f)\n", z.Zeros, z.Observed, z.Expected)
+	}
+
+	a := r.Avalanche
+	fmt.Fprintf(&b, "\nAvalanche over %d bit flips:\n", a.Trials)
+	fmt.Fprintf(&b, "Mean flip probability: %.4f, max bias: %.4f, max bit correlation: %.4f\n",
+		a.MeanFlipProbability, a.MaxBias, a.MaxBitCorrelation)
+
+	c := r.Collisions
+	fmt.Fprintf(&b, "\nCollisions on %d bits among %d distinct windows:\n", c.Bits, c.Distinct)
+	fmt.Fprintf(&b, "Observed: %d, birthday bound: %.2f\n", c.Observed, c.Expected)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
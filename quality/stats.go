@@ -0,0 +1,68 @@
+package quality
+
+import "math"
+
+const (
+	gammaMaxIterations = 100000
+	gammaEpsilon       = 1e-15
+)
+
+// Returns the probability that a chi-square variable with df degrees of
+// freedom is at least chi.
+func chiSquarePValue(chi float64, df int) float64 {
+	if df <= 0 {
+		return 1
+	}
+	return upperGamma(float64(df)/2, chi/2)
+}
+
+// The regularized upper incomplete gamma function Q(a, x), evaluated with a
+// series for x < a+1 and a continued fraction otherwise.
+func upperGamma(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+
+	lgamma, _ := math.Lgamma(a)
+	prefix := a*math.Log(x) - x - lgamma
+
+	if x < a+1 {
+		sum := 1 / a
+		term := sum
+		for n := 1; n < gammaMaxIterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*gammaEpsilon {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*math.Exp(prefix))
+	}
+
+	// Modified Lentz's method.
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < gammaMaxIterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < gammaEpsilon {
+			break
+		}
+	}
+
+	return math.Exp(prefix) * h
+}
@@ -0,0 +1,94 @@
+package simhash
+
+// Index finds all stored fingerprints within a fixed Hamming distance k of a
+// query fingerprint.
+//
+// It splits the 64 bits into k+1 disjoint blocks and keeps one table per
+// block. By the pigeonhole principle two fingerprints that differ in at most
+// k bits agree exactly on at least one block, so a query only has to verify
+// the candidates that share a block with it.
+type Index struct {
+	// The maximum distance reported by Query
+	k int
+	// The bit offset and width of every block
+	blocks []block
+	// One table per block from the block value to the fingerprints holding it
+	tables []map[uint64][]uint64
+	// All distinct fingerprints stored in the index
+	stored map[uint64]struct{}
+}
+
+type block struct {
+	shift uint
+	mask  uint64
+}
+
+// Creates an index answering queries for fingerprints at most k bits apart.
+func NewIndex(k int) (*Index, error) {
+	if k < 0 || k >= FingerprintBits {
+		return nil, ErrIllegalDistance
+	}
+
+	n := k + 1
+	idx := &Index{
+		k:      k,
+		blocks: make([]block, n),
+		tables: make([]map[uint64][]uint64, n),
+		stored: make(map[uint64]struct{}),
+	}
+
+	shift := uint(0)
+	for i := 0; i < n; i++ {
+		// Spread the remainder over the first blocks.
+		width := uint(FingerprintBits / n)
+		if i < FingerprintBits%n {
+			width++
+		}
+
+		idx.blocks[i] = block{shift: shift, mask: (1 << width) - 1}
+		idx.tables[i] = make(map[uint64][]uint64)
+		shift += width
+	}
+
+	return idx, nil
+}
+
+// Add stores the fingerprint. Adding the same fingerprint twice is a no-op.
+func (idx *Index) Add(fp uint64) {
+	if _, ok := idx.stored[fp]; ok {
+		return
+	}
+	idx.stored[fp] = struct{}{}
+
+	for i, b := range idx.blocks {
+		key := (fp >> b.shift) & b.mask
+		idx.tables[i][key] = append(idx.tables[i][key], fp)
+	}
+}
+
+// Query returns every stored fingerprint within k bits of fp, each once.
+func (idx *Index) Query(fp uint64) []uint64 {
+	var matches []uint64
+	seen := make(map[uint64]struct{})
+
+	for i, b := range idx.blocks {
+		key := (fp >> b.shift) & b.mask
+		for _, candidate := range idx.tables[i][key] {
+			if _, ok := seen[candidate]; ok {
+				continue
+			}
+			seen[candidate] = struct{}{}
+
+			if Distance(fp, candidate) <= idx.k {
+				matches = append(matches, candidate)
+			}
+		}
+	}
+
+	return matches
+}
+
+// Len returns the number of distinct fingerprints stored.
+func (idx *Index) Len() int {
+	return len(idx.stored)
+}
@@ -0,0 +1,93 @@
+// Package simhash computes 64-bit SimHash fingerprints from buzhash window
+// hashes and indexes them for near-duplicate lookups.
+//
+// A fingerprint is built by bit-voting: every window hash adds +1 to the
+// counter of each of its set bits and -1 to each of its clear bits, and the
+// fingerprint keeps the bits whose counter ended up positive. Documents that
+// share most of their windows therefore end up a small Hamming distance apart.
+package simhash
+
+import (
+	"errors"
+	"math/bits"
+
+	"github.com/satmihir/buzhash"
+)
+
+// FingerprintBits is the width of a fingerprint.
+const FingerprintBits = 64
+
+var ErrIllegalDistance = errors.New("the distance must be between 0 and 63")
+
+// Weighting controls how repeated window hashes vote.
+type Weighting int
+
+const (
+	// Every occurrence of a window hash casts a vote, so frequent windows
+	// weigh more.
+	WeightByFrequency Weighting = iota
+	// Every distinct window hash casts exactly one vote regardless of how
+	// many times it occurs.
+	Unweighted
+)
+
+// Fingerprint rolls a buzhash window of the given size over buf and returns
+// the SimHash of all window hashes.
+func Fingerprint(buf []byte, windowSize uint32, weighting Weighting) (uint64, error) {
+	h, err := buzhash.New(buf, windowSize)
+	if err != nil {
+		return 0, err
+	}
+
+	hashes, err := h.BulkRoll(1)
+	if err != nil {
+		return 0, err
+	}
+
+	return FromHashes(hashes, weighting), nil
+}
+
+// FromHashes returns the SimHash of the given window hashes, e.g. the output
+// of BulkRoll. An empty input yields a zero fingerprint.
+func FromHashes(hashes []uint64, weighting Weighting) uint64 {
+	var votes [FingerprintBits]int64
+
+	vote := func(h uint64, weight int64) {
+		for i := 0; i < FingerprintBits; i++ {
+			if (h>>i)&1 == 1 {
+				votes[i] += weight
+			} else {
+				votes[i] -= weight
+			}
+		}
+	}
+
+	if weighting == Unweighted {
+		seen := make(map[uint64]struct{}, len(hashes))
+		for _, h := range hashes {
+			if _, ok := seen[h]; ok {
+				continue
+			}
+			seen[h] = struct{}{}
+			vote(h, 1)
+		}
+	} else {
+		for _, h := range hashes {
+			vote(h, 1)
+		}
+	}
+
+	var fp uint64
+	for i := 0; i < FingerprintBits; i++ {
+		if votes[i] > 0 {
+			fp |= 1 << i
+		}
+	}
+
+	return fp
+}
+
+// Distance returns the Hamming distance between two fingerprints.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -0,0 +1,99 @@
+package simhash
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromHashesVoting(t *testing.T) {
+	// Bit 0 wins 2:1, bit 1 loses 1:2, bit 2 ties and is left clear.
+	hashes := []uint64{0b001, 0b011, 0b101}
+	fp := FromHashes(hashes, WeightByFrequency)
+	assert.Equal(t, uint64(0b001), fp&0b111)
+
+	assert.Equal(t, uint64(0), FromHashes(nil, WeightByFrequency))
+}
+
+func TestFromHashesWeighting(t *testing.T) {
+	hashes := []uint64{0b01, 0b01, 0b01, 0b10, 0b11}
+
+	// Frequency weighting lets the repeated hash dominate bit 1.
+	assert.Equal(t, uint64(0b01), FromHashes(hashes, WeightByFrequency)&0b11)
+	// Counting each distinct hash once makes bit 1 win 2:1.
+	assert.Equal(t, uint64(0b11), FromHashes(hashes, Unweighted)&0b11)
+}
+
+func TestFingerprintNearDuplicates(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	doc := make([]byte, 4096)
+	for i := range doc {
+		doc[i] = byte('a' + rng.Intn(26))
+	}
+
+	edited := append([]byte(nil), doc...)
+	copy(edited[2000:], "a small edit")
+
+	other := make([]byte, len(doc))
+	for i := range other {
+		other[i] = byte('a' + rng.Intn(26))
+	}
+
+	fpDoc, err := Fingerprint(doc, 6, WeightByFrequency)
+	assert.NoError(t, err)
+	fpEdited, err := Fingerprint(edited, 6, WeightByFrequency)
+	assert.NoError(t, err)
+	fpOther, err := Fingerprint(other, 6, WeightByFrequency)
+	assert.NoError(t, err)
+
+	assert.Less(t, Distance(fpDoc, fpEdited), 8)
+	assert.Greater(t, Distance(fpDoc, fpOther), 16)
+
+	_, err = Fingerprint([]byte("abc"), 4, WeightByFrequency)
+	assert.Error(t, err)
+}
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, Distance(0xdeadbeef, 0xdeadbeef))
+	assert.Equal(t, 64, Distance(0, ^uint64(0)))
+	assert.Equal(t, 2, Distance(0b1010, 0b0000))
+}
+
+func TestIndexQuery(t *testing.T) {
+	_, err := NewIndex(-1)
+	assert.ErrorIs(t, err, ErrIllegalDistance)
+	_, err = NewIndex(64)
+	assert.ErrorIs(t, err, ErrIllegalDistance)
+
+	rng := rand.New(rand.NewSource(7))
+	for _, k := range []int{0, 3, 7} {
+		idx, err := NewIndex(k)
+		assert.NoError(t, err)
+
+		stored := make([]uint64, 500)
+		for i := range stored {
+			stored[i] = rng.Uint64()
+			idx.Add(stored[i])
+		}
+		idx.Add(stored[0])
+		assert.Equal(t, len(stored), idx.Len())
+
+		for q := 0; q < 100; q++ {
+			query := stored[rng.Intn(len(stored))]
+			// Flip up to k+1 random bits.
+			for f := rng.Intn(k + 2); f > 0; f-- {
+				query ^= 1 << rng.Intn(64)
+			}
+
+			var want []uint64
+			for _, fp := range stored {
+				if Distance(fp, query) <= k {
+					want = append(want, fp)
+				}
+			}
+
+			assert.ElementsMatch(t, want, idx.Query(query), "k=%d query=%x", k, query)
+		}
+	}
+}